@@ -0,0 +1,199 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import "github.com/jteeuwen/go-vlc/vlcapi"
+
+// The interfaces below cover the playback related parts of Instance, Media,
+// MediaList, Player and ListPlayer. They are defined in package vlcapi, which
+// does not need cgo, so code written against them can be tested with a fake
+// implementation, such as the one in package vlctest, instead of libvlc and
+// real media.
+//
+// *Media implements MediaAPI directly. The other types exchange handles with
+// each other, so they are adapted with their API() methods:
+//
+//	inst, err := vlc.New(args)
+//	...
+//	app := NewApp(inst.API()) // NewApp accepts a vlc.InstanceAPI.
+//
+// Handles passed to the adapters must have been created by an adapter or by
+// this package; fakes can not be mixed with libvlc handles. The PlayerAPI
+// returned by Player.API() also implements TrackAPI.
+type (
+	MediaAPI      = vlcapi.MediaAPI
+	PlayerAPI     = vlcapi.PlayerAPI
+	TrackAPI      = vlcapi.TrackAPI
+	MediaListAPI  = vlcapi.MediaListAPI
+	ListPlayerAPI = vlcapi.ListPlayerAPI
+	InstanceAPI   = vlcapi.InstanceAPI
+)
+
+// Fail the build if a type or adapter falls behind its interface.
+var (
+	_ MediaAPI      = (*Media)(nil)
+	_ InstanceAPI   = instanceAPI{}
+	_ PlayerAPI     = playerAPI{}
+	_ TrackAPI      = playerAPI{}
+	_ MediaListAPI  = mediaListAPI{}
+	_ ListPlayerAPI = listPlayerAPI{}
+)
+
+// API returns this instance as an InstanceAPI.
+func (this *Instance) API() InstanceAPI { return instanceAPI{this} }
+
+// API returns this player as a PlayerAPI.
+func (this *Player) API() PlayerAPI { return playerAPI{this} }
+
+// API returns this list as a MediaListAPI.
+func (this *MediaList) API() MediaListAPI { return mediaListAPI{this} }
+
+// API returns this player as a ListPlayerAPI.
+func (this *ListPlayer) API() ListPlayerAPI { return listPlayerAPI{this} }
+
+type instanceAPI struct{ *Instance }
+
+func (this instanceAPI) OpenMediaUri(uri string) (MediaAPI, error) {
+	return mediaResult(this.Instance.OpenMediaUri(uri))
+}
+
+func (this instanceAPI) OpenMediaFile(path string) (MediaAPI, error) {
+	return mediaResult(this.Instance.OpenMediaFile(path))
+}
+
+func (this instanceAPI) NewPlayer() (PlayerAPI, error) {
+	p, err := this.Instance.NewPlayer()
+	if p == nil {
+		return nil, err
+	}
+	return p.API(), err
+}
+
+func (this instanceAPI) NewList() (MediaListAPI, error) {
+	l, err := this.Instance.NewList()
+	if l == nil {
+		return nil, err
+	}
+	return l.API(), err
+}
+
+func (this instanceAPI) NewListPlayer() (ListPlayerAPI, error) {
+	p, err := this.Instance.NewListPlayer()
+	if p == nil {
+		return nil, err
+	}
+	return p.API(), err
+}
+
+type playerAPI struct{ *Player }
+
+func (this playerAPI) Media() (MediaAPI, error) {
+	return mediaResult(this.Player.Media())
+}
+
+func (this playerAPI) SetMedia(m MediaAPI) error {
+	mm, err := toMedia(m)
+	if err != nil {
+		return err
+	}
+	return this.Player.SetMedia(mm)
+}
+
+func (this playerAPI) AudioTracks() ([]vlcapi.Track, error) {
+	return trackList(this.Player.AudioDescription())
+}
+
+func (this playerAPI) VideoTracks() ([]vlcapi.Track, error) {
+	return trackList(this.Player.VideoDescription())
+}
+
+func (this playerAPI) SubtitleTracks() ([]vlcapi.Track, error) {
+	return trackList(this.Player.SubTileDescription())
+}
+
+// trackList copies the descriptions in l.
+func trackList(l TrackDescriptionList, err error) ([]vlcapi.Track, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make([]vlcapi.Track, len(l))
+	for i, d := range l {
+		tracks[i] = vlcapi.Track{Id: d.Id(), Name: d.Name()}
+	}
+	return tracks, nil
+}
+
+type mediaListAPI struct{ *MediaList }
+
+func (this mediaListAPI) Add(m MediaAPI) error {
+	mm, err := toMedia(m)
+	if err != nil {
+		return err
+	}
+	return this.MediaList.Add(mm)
+}
+
+func (this mediaListAPI) Insert(m MediaAPI, pos int) error {
+	mm, err := toMedia(m)
+	if err != nil {
+		return err
+	}
+	return this.MediaList.Insert(mm, pos)
+}
+
+func (this mediaListAPI) At(pos int) (MediaAPI, error) {
+	return mediaResult(this.MediaList.At(pos))
+}
+
+func (this mediaListAPI) Index(m MediaAPI) (int, error) {
+	mm, err := toMedia(m)
+	if err != nil {
+		return 0, err
+	}
+	return this.MediaList.Index(mm)
+}
+
+type listPlayerAPI struct{ *ListPlayer }
+
+func (this listPlayerAPI) Set(l MediaListAPI) error {
+	ml, ok := l.(mediaListAPI)
+	if !ok {
+		return &VLCError{"MediaList was not created by libvlc"}
+	}
+	return this.ListPlayer.Set(ml.MediaList)
+}
+
+func (this listPlayerAPI) Replace(p PlayerAPI) error {
+	pp, ok := p.(playerAPI)
+	if !ok {
+		return &VLCError{"Player was not created by libvlc"}
+	}
+	return this.ListPlayer.Replace(pp.Player)
+}
+
+func (this listPlayerAPI) PlayItem(m MediaAPI) error {
+	mm, err := toMedia(m)
+	if err != nil {
+		return err
+	}
+	return this.ListPlayer.PlayItem(mm)
+}
+
+// toMedia returns the *Media behind m.
+func toMedia(m MediaAPI) (*Media, error) {
+	if mm, ok := m.(*Media); ok && mm != nil {
+		return mm, nil
+	}
+	return nil, &VLCError{"Media was not created by libvlc"}
+}
+
+// mediaResult converts a *Media result, keeping nil media a nil interface.
+func mediaResult(m *Media, err error) (MediaAPI, error) {
+	if m == nil {
+		return nil, err
+	}
+	return m, err
+}
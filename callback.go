@@ -7,14 +7,52 @@ package vlc
 // #include "glue.h"
 import "C"
 import (
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
+// Go values handed to libvlc as callback userdata are kept in this registry
+// and referred to by id. libvlc holds on to the userdata pointer long after
+// the registering call returns, so it can not be a Go pointer.
+var (
+	cbLock sync.RWMutex
+	cbData = make(map[uintptr]interface{})
+	cbNext uintptr
+)
+
+// cbRegister stores v in the callback registry and returns its id.
+func cbRegister(v interface{}) uintptr {
+	cbLock.Lock()
+	defer cbLock.Unlock()
+
+	cbNext++
+	cbData[cbNext] = v
+	return cbNext
+}
+
+// cbLookup returns the value registered under id, or nil if there is none.
+func cbLookup(id uintptr) interface{} {
+	cbLock.RLock()
+	defer cbLock.RUnlock()
+	return cbData[id]
+}
+
+// cbUnregister removes the value registered under id.
+func cbUnregister(id uintptr) {
+	cbLock.Lock()
+	delete(cbData, id)
+	cbLock.Unlock()
+}
+
 // Used when hooking/unhooking events.
 type eventData struct {
-	t C.libvlc_event_type_t
-	f EventHandler
-	d interface{}
+	t  C.libvlc_event_type_t
+	f  EventHandler
+	d  interface{}
+	id uintptr // Callback registry id.
 }
 
 // Event callback handler.
@@ -22,22 +60,44 @@ type EventHandler func(evt *Event, userdata interface{})
 
 //export goEventCB
 func goEventCB(e *C.libvlc_event_t, userdata unsafe.Pointer) {
-	evt := &Event{Type: EventType(e._type)}
-	evt.b.Write(e.u[:])
-
-	if rd := (*eventData)(userdata); rd.f != nil {
-		rd.f(evt, rd.d)
+	if rd, ok := cbLookup(uintptr(userdata)).(*eventData); ok && rd.f != nil {
+		rd.f(newEvent(e), rd.d)
 	}
 }
 
 // Used in Player.SetCallbacks() to render video to a custom memory location.
 type memRenderReq struct {
+	m  sync.Mutex
+	id uintptr
 	lh LockHandler
 	uh UnlockHandler
 	dh DisplayHandler
+	fh FormatHandler
+	ch CleanupHandler
 	ud interface{}
 }
 
+// getMemRenderReq returns a snapshot of the video callback state registered
+// under userdata, or nil if there is none.
+func getMemRenderReq(userdata unsafe.Pointer) *memRenderReq {
+	req, ok := cbLookup(uintptr(userdata)).(*memRenderReq)
+	if !ok {
+		return nil
+	}
+
+	req.m.Lock()
+	cp := &memRenderReq{
+		lh: req.lh,
+		uh: req.uh,
+		dh: req.dh,
+		fh: req.fh,
+		ch: req.ch,
+		ud: req.ud,
+	}
+	req.m.Unlock()
+	return cp
+}
+
 // Whenever a new video frame needs to be decoded, the lock callback is
 // invoked. Depending on the video chroma, one or three pixel planes of
 // adequate dimensions must be returned. Those planes must be aligned on
@@ -48,7 +108,7 @@ type LockHandler func(plane uintptr, userdata interface{}) uintptr
 
 //export goLockCB
 func goLockCB(userdata, plane unsafe.Pointer) unsafe.Pointer {
-	if req := (*memRenderReq)(userdata); req.lh != nil {
+	if req := getMemRenderReq(userdata); req != nil && req.lh != nil {
 		return unsafe.Pointer(req.lh(uintptr(plane), req.ud))
 	}
 	return nil
@@ -63,7 +123,7 @@ type UnlockHandler func(picture, plane uintptr, userdata interface{})
 
 //export goUnlockCB
 func goUnlockCB(userdata, picture, plane unsafe.Pointer) {
-	if req := (*memRenderReq)(userdata); req.uh != nil {
+	if req := getMemRenderReq(userdata); req != nil && req.uh != nil {
 		req.uh(uintptr(picture), uintptr(plane), req.ud)
 	}
 }
@@ -74,7 +134,538 @@ type DisplayHandler func(picture uintptr, userdata interface{})
 //
 // void (*display) (void* picture, void* userdata)
 func goDisplayCB(userdata, picture unsafe.Pointer) {
-	if req := (*memRenderReq)(userdata); req.dh != nil {
+	if req := getMemRenderReq(userdata); req != nil && req.dh != nil {
 		req.dh(uintptr(picture), req.ud)
 	}
 }
+
+// Maximum number of pixel planes in a picture.
+const MaxPlanes = 5
+
+// Describes the layout of decoded video pictures. See FormatHandler.
+type VideoFormat struct {
+	Chroma  string          // Four-character chroma, e.g. "RV32" or "I420".
+	Width   uint            // Pixel width.
+	Height  uint            // Pixel height.
+	Pitches [MaxPlanes]uint // Line pitch in bytes for each plane.
+	Lines   [MaxPlanes]uint // Number of lines for each plane.
+}
+
+// Whenever the video output is set up, which includes each time the source
+// resolution changes, the format callback is invoked. The format holds the
+// source chroma and dimensions, and the handler must change it to describe
+// the pictures it wants: the chroma, dimensions, and the pitch and line count
+// of every plane. Pitches should be multiples of 32 bytes.
+//
+// The handler returns the number of picture buffers it allocated, or 0 to
+// abort video output.
+//
+// unsigned (*setup) (void** opaque, char* chroma, unsigned* width, unsigned* height,
+//                    unsigned* pitches, unsigned* lines)
+type FormatHandler func(format *VideoFormat, userdata interface{}) int
+
+//export goVideoSetupCB
+func goVideoSetupCB(opaque *unsafe.Pointer, chroma *C.char, width, height, pitches, lines *C.uint) C.uint {
+	req := getMemRenderReq(*opaque)
+	if req == nil || req.fh == nil {
+		return 0
+	}
+
+	cs := unsafe.Slice((*byte)(unsafe.Pointer(chroma)), 4)
+	p := unsafe.Slice(pitches, MaxPlanes)
+	l := unsafe.Slice(lines, MaxPlanes)
+
+	vf := &VideoFormat{
+		Chroma: string(cs),
+		Width:  uint(*width),
+		Height: uint(*height),
+	}
+
+	n := req.fh(vf, req.ud)
+	if n <= 0 {
+		return 0
+	}
+
+	copy(cs, vf.Chroma+"\x00\x00\x00\x00")
+	*width, *height = C.uint(vf.Width), C.uint(vf.Height)
+
+	for i := range p {
+		p[i], l[i] = C.uint(vf.Pitches[i]), C.uint(vf.Lines[i])
+	}
+
+	return C.uint(n)
+}
+
+// When the video output is torn down, the cleanup callback is invoked. Any
+// picture buffers allocated by the FormatHandler can be released here.
+//
+// void (*cleanup) (void* opaque)
+type CleanupHandler func(userdata interface{})
+
+//export goVideoCleanupCB
+func goVideoCleanupCB(opaque unsafe.Pointer) {
+	if req := getMemRenderReq(opaque); req != nil && req.ch != nil {
+		req.ch(req.ud)
+	}
+}
+
+//export goLogCB
+func goLogCB(userdata unsafe.Pointer, level C.int, module, file *C.char, line C.uint, msg *C.char) {
+	req, ok := cbLookup(uintptr(userdata)).(*logReq)
+	if !ok || req.h == nil {
+		return
+	}
+
+	p := logPriority(int(level))
+	if !req.sink.allows(p) {
+		return
+	}
+
+	req.h(&LogMessage{
+		Priority: p,
+		Module:   C.GoString(module),
+		File:     C.GoString(file),
+		Line:     int(line),
+		Message:  C.GoString(msg),
+	}, req.d)
+}
+
+// Describes the PCM sample layout delivered to an AudioPlayHandler.
+type AudioFormat struct {
+	Format   string // Four-character sample format, e.g. "S16N" or "FL32".
+	Rate     uint   // Sample rate in Hz.
+	Channels uint   // Number of interleaved channels.
+}
+
+// SampleSize returns the size in bytes of a single sample for a single
+// channel, or 0 if the format is not known.
+func (this AudioFormat) SampleSize() int {
+	switch this.Format {
+	case "u8  ", "s8  ":
+		return 1
+	case "S16N", "S16L", "S16B", "U16N", "U16L", "U16B":
+		return 2
+	case "S24N", "S24L", "S24B":
+		return 3
+	case "S32N", "S32L", "S32B", "FL32":
+		return 4
+	case "FL64":
+		return 8
+	}
+	return 0
+}
+
+// FrameSize returns the size in bytes of one sample for all channels.
+func (this AudioFormat) FrameSize() int { return this.SampleSize() * int(this.Channels) }
+
+// Used in Player.SetAudioCallbacks() to capture decoded audio.
+type audioReq struct {
+	m      sync.Mutex
+	id     uintptr
+	format AudioFormat
+	ph     AudioPlayHandler
+	pah    AudioPauseHandler
+	rh     AudioResumeHandler
+	fh     AudioFlushHandler
+	dh     AudioDrainHandler
+	vh     AudioVolumeHandler
+	ud     interface{}
+}
+
+// getAudioReq returns a snapshot of the audio callback state registered
+// under userdata, or nil if there is none.
+func getAudioReq(userdata unsafe.Pointer) *audioReq {
+	req, ok := cbLookup(uintptr(userdata)).(*audioReq)
+	if !ok {
+		return nil
+	}
+
+	req.m.Lock()
+	cp := &audioReq{
+		format: req.format,
+		ph:     req.ph,
+		pah:    req.pah,
+		rh:     req.rh,
+		fh:     req.fh,
+		dh:     req.dh,
+		vh:     req.vh,
+		ud:     req.ud,
+	}
+	req.m.Unlock()
+	return cp
+}
+
+// Whenever decoded audio samples are ready to be played, the play callback
+// is invoked with a copy of the samples in the given format. Pts is the
+// presentation timestamp in microseconds.
+//
+// void (*play) (void* data, const void* samples, unsigned count, int64_t pts)
+type AudioPlayHandler func(samples []byte, format AudioFormat, pts int64, userdata interface{})
+
+//export goAudioPlayCB
+func goAudioPlayCB(userdata, samples unsafe.Pointer, count C.uint, pts C.int64_t) {
+	if req := getAudioReq(userdata); req != nil && req.ph != nil {
+		n := int(count) * req.format.FrameSize()
+		req.ph(C.GoBytes(samples, C.int(n)), req.format, int64(pts), req.ud)
+	}
+}
+
+// Invoked when playback is paused. Pts is the time of the pause request.
+//
+// void (*pause) (void* data, int64_t pts)
+type AudioPauseHandler func(pts int64, userdata interface{})
+
+//export goAudioPauseCB
+func goAudioPauseCB(userdata unsafe.Pointer, pts C.int64_t) {
+	if req := getAudioReq(userdata); req != nil && req.pah != nil {
+		req.pah(int64(pts), req.ud)
+	}
+}
+
+// Invoked when playback is resumed after a pause. Pts is the time of the
+// resumption request.
+//
+// void (*resume) (void* data, int64_t pts)
+type AudioResumeHandler func(pts int64, userdata interface{})
+
+//export goAudioResumeCB
+func goAudioResumeCB(userdata unsafe.Pointer, pts C.int64_t) {
+	if req := getAudioReq(userdata); req != nil && req.rh != nil {
+		req.rh(int64(pts), req.ud)
+	}
+}
+
+// Invoked when all pending samples should be discarded, e.g. when seeking.
+//
+// void (*flush) (void* data, int64_t pts)
+type AudioFlushHandler func(pts int64, userdata interface{})
+
+//export goAudioFlushCB
+func goAudioFlushCB(userdata unsafe.Pointer, pts C.int64_t) {
+	if req := getAudioReq(userdata); req != nil && req.fh != nil {
+		req.fh(int64(pts), req.ud)
+	}
+}
+
+// Invoked when all pending samples should be played out before playback
+// ends.
+//
+// void (*drain) (void* data)
+type AudioDrainHandler func(userdata interface{})
+
+//export goAudioDrainCB
+func goAudioDrainCB(userdata unsafe.Pointer) {
+	if req := getAudioReq(userdata); req != nil && req.dh != nil {
+		req.dh(req.ud)
+	}
+}
+
+// Invoked when the software volume or mute state changes. Volume is a
+// linear factor where 1.0 is the nominal level.
+//
+// void (*set_volume) (void* data, float volume, bool mute)
+type AudioVolumeHandler func(volume float32, mute bool, userdata interface{})
+
+//export goAudioVolumeCB
+func goAudioVolumeCB(userdata unsafe.Pointer, volume C.float, mute C.int) {
+	if req := getAudioReq(userdata); req != nil && req.vh != nil {
+		req.vh(float32(volume), mute != 0, req.ud)
+	}
+}
+
+// Used in Instance.OpenMediaReader() and Instance.OpenMediaReadSeeker() to
+// feed media data to libvlc.
+type readerReq struct {
+	m    sync.Mutex
+	r    io.Reader
+	s    io.Seeker // nil if the media is not seekable.
+	used bool      // True once anything was read from a non-seekable reader.
+	refs int32     // Number of live media reading from it. Accessed atomically.
+}
+
+// Callback registry ids of the readers backing each media. Duplicates of
+// reader-backed media read from the same reader, so it stays registered
+// until the last of them is freed.
+var mediaReaders = struct {
+	sync.Mutex
+	m map[*C.libvlc_media_t]uintptr
+}{m: make(map[*C.libvlc_media_t]uintptr)}
+
+// trackReader records that m reads from the reader registered under id.
+func trackReader(m *C.libvlc_media_t, id uintptr) {
+	req, ok := cbLookup(id).(*readerReq)
+	if !ok {
+		return
+	}
+
+	atomic.AddInt32(&req.refs, 1)
+
+	mediaReaders.Lock()
+	mediaReaders.m[m] = id
+	mediaReaders.Unlock()
+}
+
+// readerOf returns the registry id of the reader backing m, or 0.
+func readerOf(m *C.libvlc_media_t) uintptr {
+	mediaReaders.Lock()
+	defer mediaReaders.Unlock()
+	return mediaReaders.m[m]
+}
+
+//export goMediaOpenCB
+func goMediaOpenCB(opaque unsafe.Pointer, datap *unsafe.Pointer, sizep *C.uint64_t) C.int {
+	req, ok := cbLookup(uintptr(opaque)).(*readerReq)
+	if !ok {
+		return -1
+	}
+
+	req.m.Lock()
+	defer req.m.Unlock()
+
+	*datap = opaque
+	*sizep = C.uint64_t(math.MaxUint64)
+
+	if req.s == nil {
+		if req.used {
+			return -1
+		}
+		return 0
+	}
+
+	size, err := req.s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1
+	}
+
+	if _, err = req.s.Seek(0, io.SeekStart); err != nil {
+		return -1
+	}
+
+	*sizep = C.uint64_t(size)
+	return 0
+}
+
+//export goMediaReadCB
+func goMediaReadCB(opaque unsafe.Pointer, buf *C.uchar, size C.size_t) C.ssize_t {
+	req, ok := cbLookup(uintptr(opaque)).(*readerReq)
+	if !ok {
+		return -1
+	}
+
+	req.m.Lock()
+	defer req.m.Unlock()
+
+	req.used = true
+	p := unsafe.Slice((*byte)(unsafe.Pointer(buf)), int(size))
+
+	// A Reader may return 0, nil; libvlc would take that for end of stream.
+	for tries := 0; tries < 100; tries++ {
+		n, err := req.r.Read(p)
+
+		switch {
+		case n > 0:
+			return C.ssize_t(n)
+		case err == io.EOF:
+			return 0
+		case err != nil:
+			return -1
+		}
+	}
+
+	return -1
+}
+
+//export goMediaSeekCB
+func goMediaSeekCB(opaque unsafe.Pointer, offset C.uint64_t) C.int {
+	req, ok := cbLookup(uintptr(opaque)).(*readerReq)
+	if !ok || req.s == nil {
+		return -1
+	}
+
+	req.m.Lock()
+	defer req.m.Unlock()
+
+	if _, err := req.s.Seek(int64(offset), io.SeekStart); err != nil {
+		return -1
+	}
+
+	return 0
+}
+
+//export goMediaCloseCB
+func goMediaCloseCB(opaque unsafe.Pointer) {}
+
+// The reader state lives as long as the last media reading from it does.
+//
+//export goMediaFreedCB
+func goMediaFreedCB(e *C.libvlc_event_t, userdata unsafe.Pointer) {
+	if m := eventMedia(e); m != nil {
+		mediaReaders.Lock()
+		delete(mediaReaders.m, m.ptr)
+		mediaReaders.Unlock()
+	}
+
+	req, ok := cbLookup(uintptr(userdata)).(*readerReq)
+	if ok && atomic.AddInt32(&req.refs, -1) <= 0 {
+		cbUnregister(uintptr(userdata))
+	}
+}
+
+// Forgets the options recorded for a media which is being freed.
+//
+//export goMediaOptionsFreedCB
+func goMediaOptionsFreedCB(e *C.libvlc_event_t, userdata unsafe.Pointer) {
+	if m := eventMedia(e); m != nil {
+		mediaOptions.Lock()
+		delete(mediaOptions.m, m.ptr)
+		mediaOptions.Unlock()
+	}
+}
+
+//export goFrameSetupCB
+func goFrameSetupCB(opaque *unsafe.Pointer, chroma *C.char, width, height, pitches, lines *C.uint) C.uint {
+	sink, ok := cbLookup(uintptr(*opaque)).(*FrameSink)
+	if !ok {
+		return 0
+	}
+
+	g := sink.setup(int(*width), int(*height))
+
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(chroma)), 4), g.chroma)
+	*width, *height = C.uint(g.width), C.uint(g.height)
+
+	p := unsafe.Slice(pitches, len(g.pitches))
+	l := unsafe.Slice(lines, len(g.lines))
+
+	for i := range g.pitches {
+		p[i], l[i] = C.uint(g.pitches[i]), C.uint(g.lines[i])
+	}
+
+	return 1
+}
+
+//export goFrameCleanupCB
+func goFrameCleanupCB(opaque unsafe.Pointer) {
+	if sink, ok := cbLookup(uintptr(opaque)).(*FrameSink); ok {
+		sink.cleanup()
+	}
+}
+
+//export goFrameLockCB
+func goFrameLockCB(opaque unsafe.Pointer, planes *unsafe.Pointer) unsafe.Pointer {
+	if sink, ok := cbLookup(uintptr(opaque)).(*FrameSink); ok {
+		pl := sink.planes()
+		copy(unsafe.Slice(planes, len(pl)), pl[:])
+	}
+	return nil
+}
+
+//export goFrameDisplayCB
+func goFrameDisplayCB(opaque, picture unsafe.Pointer) {
+	if sink, ok := cbLookup(uintptr(opaque)).(*FrameSink); ok {
+		sink.display()
+	}
+}
+
+//export goFrameTimeCB
+func goFrameTimeCB(e *C.libvlc_event_t, userdata unsafe.Pointer) {
+	if sink, ok := cbLookup(uintptr(userdata)).(*FrameSink); ok {
+		sink.setTime(newEvent(e).Payload().(*TimeChangedEvent).Time)
+	}
+}
+
+//export goDialogErrorCB
+func goDialogErrorCB(userdata C.uintptr_t, title, text *C.char) {
+	if req, ok := cbLookup(uintptr(userdata)).(*dialogReq); ok {
+		req.h.DisplayError(C.GoString(title), C.GoString(text))
+	}
+}
+
+//export goDialogLoginCB
+func goDialogLoginCB(userdata C.uintptr_t, id *C.libvlc_dialog_id, title, text, username *C.char, store C.int) {
+	req, ok := cbLookup(uintptr(userdata)).(*dialogReq)
+	if !ok {
+		C.libvlc_dialog_dismiss(id)
+		return
+	}
+
+	d := &LoginDialog{AskStore: store != 0}
+	if username != nil {
+		d.DefaultUsername = C.GoString(username)
+	}
+
+	d.attach(id, C.GoString(title), C.GoString(text), d)
+	req.h.DisplayLogin(d)
+}
+
+//export goDialogQuestionCB
+func goDialogQuestionCB(userdata C.uintptr_t, id *C.libvlc_dialog_id, title, text *C.char, qt C.int, cancel, action1, action2 *C.char) {
+	req, ok := cbLookup(uintptr(userdata)).(*dialogReq)
+	if !ok {
+		C.libvlc_dialog_dismiss(id)
+		return
+	}
+
+	d := &QuestionDialog{Type: QuestionType(qt)}
+	if cancel != nil {
+		d.CancelLabel = C.GoString(cancel)
+	}
+	if action1 != nil {
+		d.Action1 = C.GoString(action1)
+	}
+	if action2 != nil {
+		d.Action2 = C.GoString(action2)
+	}
+
+	d.attach(id, C.GoString(title), C.GoString(text), d)
+	req.h.DisplayQuestion(d)
+}
+
+//export goDialogProgressCB
+func goDialogProgressCB(userdata C.uintptr_t, id *C.libvlc_dialog_id, title, text *C.char, indeterminate C.int, position C.float, cancel *C.char) {
+	req, ok := cbLookup(uintptr(userdata)).(*dialogReq)
+	if !ok {
+		C.libvlc_dialog_dismiss(id)
+		return
+	}
+
+	d := &ProgressDialog{Indeterminate: indeterminate != 0, position: float32(position)}
+	if cancel != nil {
+		d.CancelLabel = C.GoString(cancel)
+	}
+
+	d.attach(id, C.GoString(title), C.GoString(text), d)
+	req.h.DisplayProgress(d)
+}
+
+//export goDialogCancelCB
+func goDialogCancelCB(userdata, ctx C.uintptr_t) {
+	d, ok := cbLookup(uintptr(ctx)).(Dialog)
+	if !ok {
+		return
+	}
+
+	if req, ok := cbLookup(uintptr(userdata)).(*dialogReq); ok {
+		req.h.Cancel(d)
+	}
+
+	d.Dismiss()
+}
+
+//export goDialogUpdateCB
+func goDialogUpdateCB(userdata, ctx C.uintptr_t, position C.float, text *C.char) {
+	d, ok := cbLookup(uintptr(ctx)).(*ProgressDialog)
+	if !ok {
+		return
+	}
+
+	t := d.Text()
+	if text != nil {
+		t = C.GoString(text)
+	}
+	d.update(float32(position), t)
+
+	if req, ok := cbLookup(uintptr(userdata)).(*dialogReq); ok {
+		req.h.UpdateProgress(d)
+	}
+}
@@ -0,0 +1,97 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	vlc "github.com/jteeuwen/go-vlc"
+)
+
+var profiles = map[string]vlc.TranscodeProfile{
+	"mp4": vlc.ProfileMP4,
+	"ts":  vlc.ProfileTS,
+	"ogg": vlc.ProfileOgg,
+	"mp3": vlc.ProfileMP3,
+}
+
+func runConvert(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	profile := fs.String("profile", "mp4", "base settings: mp4, ts, ogg or mp3")
+	mux := fs.String("mux", "", "container format, e.g. mp4, ts or ogg")
+	vcodec := fs.String("vcodec", "", "video codec fourcc, e.g. h264")
+	vb := fs.Int("vb", 0, "video bitrate in kbit/s")
+	scale := fs.Float64("scale", 0, "video scale factor")
+	width := fs.Int("width", 0, "video width in pixels")
+	height := fs.Int("height", 0, "video height in pixels")
+	acodec := fs.String("acodec", "", "audio codec fourcc, e.g. mp4a")
+	ab := fs.Int("ab", 0, "audio bitrate in kbit/s")
+	quiet := fs.Bool("q", false, "do not show progress")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return errUsage
+	}
+
+	p, ok := profiles[*profile]
+	if !ok {
+		return fmt.Errorf("unknown profile %q", *profile)
+	}
+
+	// Flags override the profile where they are set.
+	tc := &p.Transcode
+	setString := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+
+	setInt := func(dst *int, v int) {
+		if v > 0 {
+			*dst = v
+		}
+	}
+
+	setString(&p.Mux, *mux)
+	setString(&tc.VCodec, *vcodec)
+	setString(&tc.ACodec, *acodec)
+	setInt(&tc.VBitrate, *vb)
+	setInt(&tc.Width, *width)
+	setInt(&tc.Height, *height)
+	setInt(&tc.ABitrate, *ab)
+
+	if *scale > 0 {
+		tc.Scale = *scale
+	}
+
+	if !*quiet {
+		p.Progress = func(pos float32) {
+			fmt.Fprintf(os.Stderr, "\r%5.1f%% ", pos*100)
+		}
+	}
+
+	inst, err := newInstance()
+	if err != nil {
+		return err
+	}
+
+	defer inst.Release()
+
+	r, err := vlc.Transcode(ctx, inst, fs.Arg(0), fs.Arg(1), p)
+
+	if !*quiet {
+		fmt.Fprintln(os.Stderr)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d bytes in %s\n", r.Output, r.Size, r.Elapsed.Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,149 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+// Command govlc inspects, plays, snapshots and converts media with libvlc.
+//
+// Usage:
+//
+//	govlc [-v level] command [flags] input ...
+//
+// The commands are:
+//
+//	probe     print metadata, tracks, duration and statistics as JSON
+//	play      play media without video output, showing progress
+//	snapshot  write video frames at the given times as images
+//	convert   transcode media to a file
+//
+// Run "govlc command -h" for the flags of a command. Inputs are local paths
+// or URIs such as "http://example.com/a.ogg". Interrupting govlc stops
+// playback cleanly.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	vlc "github.com/jteeuwen/go-vlc"
+)
+
+// A govlc subcommand.
+type command struct {
+	name  string
+	args  string // Usage of the arguments, e.g. "[flags] input".
+	short string // One line description.
+
+	// run defines the command's flags on fs, parses args with it and runs
+	// the command.
+	run func(ctx context.Context, fs *flag.FlagSet, args []string) error
+}
+
+var commands = []*command{
+	{"probe", "[flags] input", "print metadata, tracks, duration and statistics as JSON", runProbe},
+	{"play", "[flags] input", "play media without video output, showing progress", runPlay},
+	{"snapshot", "[flags] input time ...", "write video frames at the given times as images", runSnapshot},
+	{"convert", "[flags] input output", "transcode media to a file", runConvert},
+}
+
+// Returned by a command if its arguments are invalid.
+var errUsage = errors.New("invalid arguments")
+
+var verbose = flag.Int("v", 0, "libvlc verbosity: 0 is quiet, 1 shows errors, 2 warnings and 3 debug messages")
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for _, c := range commands {
+		if c.name == flag.Arg(0) {
+			cmd = c
+		}
+	}
+
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "govlc: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: govlc %s %s\n\n%s.\n\nFlags:\n", cmd.name, cmd.args, cmd.short)
+		fs.PrintDefaults()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := cmd.run(ctx, fs, flag.Args()[1:])
+
+	switch {
+	case err == nil:
+	case err == errUsage:
+		fs.Usage()
+		os.Exit(2)
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintf(os.Stderr, "govlc %s: interrupted\n", cmd.name)
+		os.Exit(130)
+	default:
+		fmt.Fprintf(os.Stderr, "govlc %s: %v\n", cmd.name, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: govlc [-v level] command [flags] input ...\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", c.name, c.short)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// newInstance creates a libvlc instance with the verbosity selected by -v.
+func newInstance() (*vlc.Instance, error) {
+	args := []string{"--no-video-title-show"}
+
+	if *verbose > 0 {
+		args = append(args, fmt.Sprintf("--verbose=%d", *verbose-1))
+	} else {
+		args = append(args, "--quiet")
+	}
+
+	return vlc.New(args)
+}
+
+// openMedia opens a local path or a URI.
+func openMedia(inst *vlc.Instance, input string) (*vlc.Media, error) {
+	if strings.Contains(input, "://") {
+		return inst.OpenMediaUri(input)
+	}
+	return inst.OpenMediaFile(input)
+}
+
+// clock formats d as h:mm:ss, or m:ss if it is shorter than an hour.
+func clock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	s := int(d / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
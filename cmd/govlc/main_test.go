@@ -0,0 +1,31 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	for _, tt := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0:00"},
+		{-time.Second, "0:00"},
+		{999 * time.Millisecond, "0:00"},
+		{5 * time.Second, "0:05"},
+		{61 * time.Second, "1:01"},
+		{59*time.Minute + 59*time.Second, "59:59"},
+		{time.Hour, "1:00:00"},
+		{time.Hour + 2*time.Minute + 3*time.Second, "1:02:03"},
+		{25 * time.Hour, "25:00:00"},
+	} {
+		if have := clock(tt.d); have != tt.want {
+			t.Errorf("clock(%v): have %q, want %q", tt.d, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	vlc "github.com/jteeuwen/go-vlc"
+)
+
+func runPlay(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	start := fs.Duration("start", 0, "start playing at this time")
+	limit := fs.Duration("for", 0, "stop after playing this long; 0 plays to the end")
+	rate := fs.Float64("rate", 1, "playback rate")
+	noAudio := fs.Bool("no-audio", false, "do not play audio either")
+	quiet := fs.Bool("q", false, "do not show progress")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *rate <= 0 {
+		return errUsage
+	}
+
+	inst, err := newInstance()
+	if err != nil {
+		return err
+	}
+
+	defer inst.Release()
+
+	m, err := openMedia(inst, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	defer m.Release()
+
+	m.AddOption(":no-video")
+
+	if *noAudio {
+		m.AddOption(":no-audio")
+	}
+
+	if *start > 0 {
+		m.AddOption(fmt.Sprintf(":start-time=%.3f", start.Seconds()))
+	}
+
+	p, err := m.NewPlayer()
+	if err != nil {
+		return err
+	}
+
+	defer p.Release()
+
+	if err = p.SetRate(float32(*rate)); err != nil {
+		return err
+	}
+
+	if *limit > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, *limit)
+		defer cancel()
+	}
+
+	if err = p.PlayAndWait(ctx); err != nil {
+		return playResult(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.WaitForState(ctx, vlc.MSEnded)
+		done <- err
+	}()
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+
+	for {
+		if !*quiet {
+			showProgress(p)
+		}
+
+		select {
+		case err = <-done:
+			if !*quiet {
+				showProgress(p)
+				fmt.Fprintln(os.Stderr)
+			}
+			return playResult(err)
+
+		case <-tick.C:
+		}
+	}
+}
+
+// playResult maps the end of playback by the -for limit to success.
+func playResult(err error) error {
+	if err == context.DeadlineExceeded {
+		return nil
+	}
+	return err
+}
+
+// showProgress overwrites the current line of stderr with the playing time.
+func showProgress(p *vlc.Player) {
+	t, _ := p.Elapsed()
+	l, _ := p.MediaLength()
+
+	if l > 0 {
+		fmt.Fprintf(os.Stderr, "\r%s / %s (%.0f%%) ", clock(t), clock(l), 100*float64(t)/float64(l))
+	} else {
+		fmt.Fprintf(os.Stderr, "\r%s ", clock(t))
+	}
+}
@@ -0,0 +1,240 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+
+	vlc "github.com/jteeuwen/go-vlc"
+)
+
+// Output of the probe command.
+type probeResult struct {
+	Mrl      string            `json:"mrl"`
+	Status   string            `json:"status"`             // Parse status.
+	Duration float64           `json:"duration,omitempty"` // Seconds.
+	Meta     map[string]string `json:"meta,omitempty"`
+	Tracks   []*probeTrack     `json:"tracks,omitempty"`
+	Stats    *probeStats       `json:"stats,omitempty"`
+}
+
+type probeTrack struct {
+	Type        string  `json:"type"`
+	Id          int     `json:"id"`
+	Codec       string  `json:"codec"`
+	Bitrate     uint    `json:"bitrate,omitempty"`
+	Language    string  `json:"language,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Channels    uint    `json:"channels,omitempty"`
+	Rate        uint    `json:"rate,omitempty"`
+	Width       uint    `json:"width,omitempty"`
+	Height      uint    `json:"height,omitempty"`
+	FrameRate   float64 `json:"frame_rate,omitempty"`
+	Encoding    string  `json:"encoding,omitempty"`
+}
+
+type probeStats struct {
+	ReadBytes          int     `json:"read_bytes"`
+	InputBitRate       float32 `json:"input_bitrate"`
+	DemuxReadBytes     int     `json:"demux_read_bytes"`
+	DemuxBitRate       float32 `json:"demux_bitrate"`
+	DemuxCorrupted     int     `json:"demux_corrupted"`
+	DemuxDiscontinuity int     `json:"demux_discontinuity"`
+	DecodedVideo       int     `json:"decoded_video"`
+	DecodedAudio       int     `json:"decoded_audio"`
+	DisplayedPictures  int     `json:"displayed_pictures"`
+	LostPictures       int     `json:"lost_pictures"`
+	PlayedAudioBuffers int     `json:"played_audio_buffers"`
+	LostAudioBuffers   int     `json:"lost_audio_buffers"`
+}
+
+var parsedStatusNames = map[vlc.ParsedStatus]string{
+	vlc.PSNone:    "none",
+	vlc.PSSkipped: "skipped",
+	vlc.PSFailed:  "failed",
+	vlc.PSTimeout: "timeout",
+	vlc.PSDone:    "done",
+}
+
+var metaNames = map[vlc.MetaProperty]string{
+	vlc.MPTitle:       "title",
+	vlc.MPArtist:      "artist",
+	vlc.MPGenre:       "genre",
+	vlc.MPCopyright:   "copyright",
+	vlc.MPAlbum:       "album",
+	vlc.MPTrackNumber: "track_number",
+	vlc.MPDescription: "description",
+	vlc.MPRating:      "rating",
+	vlc.MPDate:        "date",
+	vlc.MPSetting:     "setting",
+	vlc.MPURL:         "url",
+	vlc.MPLanguage:    "language",
+	vlc.MPNowPlaying:  "now_playing",
+	vlc.MPPublisher:   "publisher",
+	vlc.MPEncodedBy:   "encoded_by",
+	vlc.MPArtworkURL:  "artwork_url",
+	vlc.MPTrackID:     "track_id",
+}
+
+var trackTypeNames = map[vlc.TrackType]string{
+	vlc.TTAudio: "audio",
+	vlc.TTVideo: "video",
+	vlc.TTText:  "subtitle",
+}
+
+func runProbe(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	network := fs.Bool("network", false, "parse network media, and fetch metadata from the network")
+	timeout := fs.Duration("timeout", 0, "maximum parse time; 0 selects libvlc's default")
+	sample := fs.Duration("sample", 0, "play the media without output for this long before printing; "+
+		"statistics, and the tracks of some formats, are only known during playback")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+
+	inst, err := newInstance()
+	if err != nil {
+		return err
+	}
+
+	defer inst.Release()
+
+	m, err := openMedia(inst, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	defer m.Release()
+
+	opts := vlc.ParseOptions{Flags: vlc.FetchLocal, Timeout: *timeout}
+	if *network {
+		opts.Flags |= vlc.ParseNetwork | vlc.FetchNetwork
+	}
+
+	status, err := m.ParseContext(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if *sample > 0 {
+		// Decode, but do not show or play anything.
+		m.AddOption(":vout=dummy")
+		m.AddOption(":aout=dummy")
+
+		p, err := m.NewPlayer()
+		if err != nil {
+			return err
+		}
+
+		// Releasing the player stops it.
+		defer p.Release()
+
+		if err = samplePlayback(ctx, p, *sample); err != nil {
+			return err
+		}
+	}
+
+	r := &probeResult{
+		Mrl:    m.Mrl(),
+		Status: parsedStatusNames[status],
+		Meta:   make(map[string]string),
+	}
+
+	if d, err := m.Length(); err == nil {
+		r.Duration = d.Seconds()
+	}
+
+	for k, name := range metaNames {
+		if v := m.Meta(k); v != "" {
+			r.Meta[name] = v
+		}
+	}
+
+	tracks, err := m.Tracks()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tracks {
+		r.Tracks = append(r.Tracks, newProbeTrack(t))
+	}
+
+	if s, err := m.Stats(); err == nil {
+		r.Stats = newProbeStats(s)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// samplePlayback plays for d, or until the end of the media.
+func samplePlayback(ctx context.Context, p *vlc.Player, d time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	err := p.PlayAndWait(ctx)
+	if err == nil {
+		_, err = p.WaitForState(ctx, vlc.MSEnded)
+	}
+
+	if err == context.DeadlineExceeded {
+		return nil
+	}
+	return err
+}
+
+func newProbeTrack(t vlc.MediaTrack) *probeTrack {
+	info := t.Info()
+
+	pt := &probeTrack{
+		Type:        trackTypeNames[info.Type],
+		Id:          info.Id,
+		Codec:       info.CodecName(),
+		Bitrate:     info.Bitrate,
+		Language:    info.Language,
+		Description: info.Description,
+	}
+
+	if pt.Type == "" {
+		pt.Type = "unknown"
+	}
+
+	switch t := t.(type) {
+	case *vlc.AudioTrack:
+		pt.Channels = t.Channels
+		pt.Rate = t.Rate
+	case *vlc.VideoTrack:
+		pt.Width = t.Width
+		pt.Height = t.Height
+		pt.FrameRate = t.FrameRate()
+	case *vlc.SubtitleTrack:
+		pt.Encoding = t.Encoding
+	}
+
+	return pt
+}
+
+func newProbeStats(s *vlc.Stats) *probeStats {
+	return &probeStats{
+		ReadBytes:          s.ReadBytes(),
+		InputBitRate:       s.InputBitRate(),
+		DemuxReadBytes:     s.DemuxReadBytes(),
+		DemuxBitRate:       s.DemuxBitRate(),
+		DemuxCorrupted:     s.DemuxCorrupted(),
+		DemuxDiscontinuity: s.DemuxDiscontinuity(),
+		DecodedVideo:       s.DecodedVideo(),
+		DecodedAudio:       s.DecodedAudio(),
+		DisplayedPictures:  s.DisplayedPictures(),
+		LostPictures:       s.LostPictures(),
+		PlayedAudioBuffers: s.PlayedAudioBuffers(),
+		LostAudioBuffers:   s.LostAudioBuffers(),
+	}
+}
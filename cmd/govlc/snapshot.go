@@ -0,0 +1,124 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	vlc "github.com/jteeuwen/go-vlc"
+)
+
+func runSnapshot(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	width := fs.Uint("width", 0, "image width; 0 derives it from the height or the source")
+	height := fs.Uint("height", 0, "image height; 0 derives it from the width or the source")
+	crop := fs.Bool("crop", false, "crop to exactly width x height, instead of fitting within it")
+	fast := fs.Bool("fast", false, "take frames from the nearest key frame, which is quicker but less accurate")
+	format := fs.String("format", "png", "image format: png or jpeg")
+	prefix := fs.String("o", "", "output file prefix; defaults to the input name without extension")
+	fs.Usage = snapshotUsage(fs.Usage)
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		return errUsage
+	}
+
+	var ifmt vlc.ImageFormat
+	switch *format {
+	case "png":
+		ifmt = vlc.IFPNG
+	case "jpeg", "jpg":
+		ifmt, *format = vlc.IFJPEG, "jpg"
+	default:
+		return errUsage
+	}
+
+	input := fs.Arg(0)
+	if *prefix == "" {
+		*prefix = strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	}
+
+	opts := vlc.ThumbnailOptions{Width: *width, Height: *height, Crop: *crop}
+	if *fast {
+		opts.Seek = vlc.SeekFast
+	}
+
+	// Check all times before starting.
+	for _, s := range fs.Args()[1:] {
+		if _, _, err := parsePoint(s); err != nil {
+			return err
+		}
+	}
+
+	inst, err := newInstance()
+	if err != nil {
+		return err
+	}
+
+	defer inst.Release()
+
+	m, err := openMedia(inst, input)
+	if err != nil {
+		return err
+	}
+
+	defer m.Release()
+
+	for i, s := range fs.Args()[1:] {
+		opts.Time, opts.Position, _ = parsePoint(s)
+
+		data, err := m.EncodeThumbnail(ctx, opts, ifmt)
+		if err != nil {
+			return fmt.Errorf("%s: %v", s, err)
+		}
+
+		name := fmt.Sprintf("%s-%d.%s", *prefix, i+1, *format)
+		if err = os.WriteFile(name, data, 0644); err != nil {
+			return err
+		}
+
+		fmt.Println(name)
+	}
+
+	return nil
+}
+
+func snapshotUsage(usage func()) func() {
+	return func() {
+		usage()
+		fmt.Fprintf(os.Stderr, "\nTimes are durations such as 1m30s, seconds such as 90.5, or\n"+
+			"percentages of the media duration such as 50%%. Frames are written to\n"+
+			"prefix-1.png, prefix-2.png and so on, in the order given.\n")
+	}
+}
+
+// parsePoint parses a snapshot time: a duration, a number of seconds or a
+// percentage of the media duration. Either the time or the position is set.
+func parsePoint(s string) (time.Duration, float32, error) {
+	if v := strings.TrimSuffix(s, "%"); v != s {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil || f < 0 || f > 100 {
+			return 0, 0, fmt.Errorf("invalid percentage %q", s)
+		}
+		return 0, float32(f / 100), nil
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f >= 0 {
+		return time.Duration(f * float64(time.Second)), 0, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	return d, 0, nil
+}
@@ -0,0 +1,47 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePoint(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		at  time.Duration
+		pos float32
+		ok  bool
+	}{
+		{"0", 0, 0, true},
+		{"90", 90 * time.Second, 0, true},
+		{"1.5", 1500 * time.Millisecond, 0, true},
+		{"1m30s", 90 * time.Second, 0, true},
+		{"250ms", 250 * time.Millisecond, 0, true},
+		{"50%", 0, 0.5, true},
+		{"0%", 0, 0, true},
+		{"100%", 0, 1, true},
+		{"12.5%", 0, 0.125, true},
+		{"101%", 0, 0, false},
+		{"-1%", 0, 0, false},
+		{"%", 0, 0, false},
+		{"-5", 0, 0, false},
+		{"-1s", 0, 0, false},
+		{"1m%", 0, 0, false},
+		{"", 0, 0, false},
+		{"soon", 0, 0, false},
+	} {
+		at, pos, err := parsePoint(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("parsePoint(%q): have error %v, want ok %v", tt.in, err, tt.ok)
+			continue
+		}
+
+		if at != tt.at || pos != tt.pos {
+			t.Errorf("parsePoint(%q): have %v, %v, want %v, %v", tt.in, at, pos, tt.at, tt.pos)
+		}
+	}
+}
@@ -4,92 +4,168 @@
 
 package vlc
 
-type LogPriority uint8
+import "github.com/jteeuwen/go-vlc/vlcapi"
+
+// The types below are defined in package vlcapi, so that code using them
+// does not need cgo. See there for their documentation.
+type (
+	EventType    = vlcapi.EventType
+	PlaybackMode = vlcapi.PlaybackMode
+	MetaProperty = vlcapi.MetaProperty
+	MediaState   = vlcapi.MediaState
+	ParsedStatus = vlcapi.ParsedStatus
+	SeekMode     = vlcapi.SeekMode
+	TrackType    = vlcapi.TrackType
+)
 
 const (
-	Info LogPriority = iota
-	Error
-	Warning
-	Debug
+	MediaMetaChanged      = vlcapi.MediaMetaChanged
+	MediaSubItemAdded     = vlcapi.MediaSubItemAdded
+	MediaDurationChanged  = vlcapi.MediaDurationChanged
+	MediaParsedChanged    = vlcapi.MediaParsedChanged
+	MediaFreed            = vlcapi.MediaFreed
+	MediaStateChanged     = vlcapi.MediaStateChanged
+	MediaSubItemTreeAdded = vlcapi.MediaSubItemTreeAdded
+)
+
+const (
+	MediaPlayerMediaChanged     = vlcapi.MediaPlayerMediaChanged
+	MediaPlayerNothingSpecial   = vlcapi.MediaPlayerNothingSpecial
+	MediaPlayerOpening          = vlcapi.MediaPlayerOpening
+	MediaPlayerBuffering        = vlcapi.MediaPlayerBuffering
+	MediaPlayerPlaying          = vlcapi.MediaPlayerPlaying
+	MediaPlayerPaused           = vlcapi.MediaPlayerPaused
+	MediaPlayerStopped          = vlcapi.MediaPlayerStopped
+	MediaPlayerForward          = vlcapi.MediaPlayerForward
+	MediaPlayerBackward         = vlcapi.MediaPlayerBackward
+	MediaPlayerEndReached       = vlcapi.MediaPlayerEndReached
+	MediaPlayerEncounteredError = vlcapi.MediaPlayerEncounteredError
+	MediaPlayerTimeChanged      = vlcapi.MediaPlayerTimeChanged
+	MediaPlayerPositionChanged  = vlcapi.MediaPlayerPositionChanged
+	MediaPlayerSeekableChanged  = vlcapi.MediaPlayerSeekableChanged
+	MediaPlayerPausableChanged  = vlcapi.MediaPlayerPausableChanged
+	MediaPlayerTitleChanged     = vlcapi.MediaPlayerTitleChanged
+	MediaPlayerSnapshotTaken    = vlcapi.MediaPlayerSnapshotTaken
+	MediaPlayerLengthChanged    = vlcapi.MediaPlayerLengthChanged
+	MediaPlayerVout             = vlcapi.MediaPlayerVout
+	MediaPlayerScrambledChanged = vlcapi.MediaPlayerScrambledChanged
+	MediaPlayerESAdded          = vlcapi.MediaPlayerESAdded
+	MediaPlayerESDeleted        = vlcapi.MediaPlayerESDeleted
+	MediaPlayerESSelected       = vlcapi.MediaPlayerESSelected
+	MediaPlayerCorked           = vlcapi.MediaPlayerCorked
+	MediaPlayerUncorked         = vlcapi.MediaPlayerUncorked
+	MediaPlayerMuted            = vlcapi.MediaPlayerMuted
+	MediaPlayerUnmuted          = vlcapi.MediaPlayerUnmuted
+	MediaPlayerAudioVolume      = vlcapi.MediaPlayerAudioVolume
+	MediaPlayerAudioDevice      = vlcapi.MediaPlayerAudioDevice
+	MediaPlayerChapterChanged   = vlcapi.MediaPlayerChapterChanged
+)
+
+const (
+	MediaListItemAdded      = vlcapi.MediaListItemAdded
+	MediaListWillAddItem    = vlcapi.MediaListWillAddItem
+	MediaListItemDeleted    = vlcapi.MediaListItemDeleted
+	MediaListWillDeleteItem = vlcapi.MediaListWillDeleteItem
+	MediaListEndReached     = vlcapi.MediaListEndReached
+)
+
+const (
+	MediaListViewItemAdded      = vlcapi.MediaListViewItemAdded
+	MediaListViewWillAddItem    = vlcapi.MediaListViewWillAddItem
+	MediaListViewItemDeleted    = vlcapi.MediaListViewItemDeleted
+	MediaListViewWillDeleteItem = vlcapi.MediaListViewWillDeleteItem
+)
+
+const (
+	MediaListPlayerPlayed      = vlcapi.MediaListPlayerPlayed
+	MediaListPlayerNextItemSet = vlcapi.MediaListPlayerNextItemSet
+	MediaListPlayerStopped     = vlcapi.MediaListPlayerStopped
 )
 
-type EventType int
+const (
+	MediaDiscovererStarted = vlcapi.MediaDiscovererStarted
+	MediaDiscovererEnded   = vlcapi.MediaDiscovererEnded
+)
 
 const (
-	MediaMetaChanged EventType = iota
-	MediaSubItemAdded
-	MediaDurationChanged
-	MediaParsedChanged
-	MediaFreed
-	MediaStateChanged
+	VlmMediaAdded                 = vlcapi.VlmMediaAdded
+	VlmMediaRemoved               = vlcapi.VlmMediaRemoved
+	VlmMediaChanged               = vlcapi.VlmMediaChanged
+	VlmMediaInstanceStarted       = vlcapi.VlmMediaInstanceStarted
+	VlmMediaInstanceStopped       = vlcapi.VlmMediaInstanceStopped
+	VlmMediaInstanceStatusInit    = vlcapi.VlmMediaInstanceStatusInit
+	VlmMediaInstanceStatusOpening = vlcapi.VlmMediaInstanceStatusOpening
+	VlmMediaInstanceStatusPlaying = vlcapi.VlmMediaInstanceStatusPlaying
+	VlmMediaInstanceStatusPause   = vlcapi.VlmMediaInstanceStatusPause
+	VlmMediaInstanceStatusEnd     = vlcapi.VlmMediaInstanceStatusEnd
+	VlmMediaInstanceStatusError   = vlcapi.VlmMediaInstanceStatusError
 )
 
 const (
-	MediaPlayerMediaChanged EventType = 0x100 + iota
-	MediaPlayerNothingSpecial
-	MediaPlayerOpening
-	MediaPlayerBuffering
-	MediaPlayerPlaying
-	MediaPlayerPaused
-	MediaPlayerStopped
-	MediaPlayerForward
-	MediaPlayerBackward
-	MediaPlayerEndReached
-	MediaPlayerEncounteredError
-	MediaPlayerTimeChanged
-	MediaPlayerPositionChanged
-	MediaPlayerSeekableChanged
-	MediaPlayerPausableChanged
-	MediaPlayerTitleChanged
-	MediaPlayerSnapshotTaken
-	MediaPlayerLengthChanged
+	PMDefault = vlcapi.PMDefault
+	PMLoop    = vlcapi.PMLoop
+	PMRepeat  = vlcapi.PMRepeat
 )
 
 const (
-	MediaListItemAdded EventType = 0x200 + iota
-	MediaListWillAddItem
-	MediaListItemDeleted
-	MediaListWillDeleteItem
+	MPTitle       = vlcapi.MPTitle
+	MPArtist      = vlcapi.MPArtist
+	MPGenre       = vlcapi.MPGenre
+	MPCopyright   = vlcapi.MPCopyright
+	MPAlbum       = vlcapi.MPAlbum
+	MPTrackNumber = vlcapi.MPTrackNumber
+	MPDescription = vlcapi.MPDescription
+	MPRating      = vlcapi.MPRating
+	MPDate        = vlcapi.MPDate
+	MPSetting     = vlcapi.MPSetting
+	MPURL         = vlcapi.MPURL
+	MPLanguage    = vlcapi.MPLanguage
+	MPNowPlaying  = vlcapi.MPNowPlaying
+	MPPublisher   = vlcapi.MPPublisher
+	MPEncodedBy   = vlcapi.MPEncodedBy
+	MPArtworkURL  = vlcapi.MPArtworkURL
+	MPTrackID     = vlcapi.MPTrackID
 )
 
 const (
-	MediaListViewItemAdded EventType = 0x300 + iota
-	MediaListViewWillAddItem
-	MediaListViewItemDeleted
-	MediaListViewWillDeleteItem
+	MSNothingSpecial = vlcapi.MSNothingSpecial
+	MSOpening        = vlcapi.MSOpening
+	MSBuffering      = vlcapi.MSBuffering
+	MSPlaying        = vlcapi.MSPlaying
+	MSPaused         = vlcapi.MSPaused
+	MSStopped        = vlcapi.MSStopped
+	MSEnded          = vlcapi.MSEnded
+	MSError          = vlcapi.MSError
 )
 
 const (
-	MediaListPlayerPlayed EventType = 0x400 + iota
-	MediaListPlayerNextItemSet
-	MediaListPlayerStopped
+	PSNone    = vlcapi.PSNone
+	PSSkipped = vlcapi.PSSkipped
+	PSFailed  = vlcapi.PSFailed
+	PSTimeout = vlcapi.PSTimeout
+	PSDone    = vlcapi.PSDone
 )
 
 const (
-	MediaDiscovererStarted EventType = 0x500 + iota
-	MediaDiscovererEnded
+	SeekPrecise = vlcapi.SeekPrecise
+	SeekFast    = vlcapi.SeekFast
 )
 
 const (
-	VlmMediaAdded EventType = 0x600 + iota
-	VlmMediaRemoved
-	VlmMediaChanged
-	VlmMediaInstanceStarted
-	VlmMediaInstanceStopped
-	VlmMediaInstanceStatusInit
-	VlmMediaInstanceStatusOpening
-	VlmMediaInstanceStatusPlaying
-	VlmMediaInstanceStatusPause
-	VlmMediaInstanceStatusEnd
-	VlmMediaInstanceStatusError
+	TTUnknown = vlcapi.TTUnknown
+	TTAudio   = vlcapi.TTAudio
+	TTVideo   = vlcapi.TTVideo
+	TTText    = vlcapi.TTText
 )
 
-type PlaybackMode uint8
+// Severity of a libvlc log message.
+type LogPriority uint8
 
 const (
-	PMDefault PlaybackMode = iota
-	PMLoop
-	PMRepeat
+	Info LogPriority = iota
+	Error
+	Warning
+	Debug
 )
 
 type MarqueeOption uint8
@@ -131,39 +207,41 @@ const (
 	LOPosition
 )
 
-type MetaProperty uint8
+// Severity of a QuestionDialog.
+type QuestionType int
 
 const (
-	MPTitle MetaProperty = iota
-	MPArtist
-	MPGenre
-	MPCopyright
-	MPAlbum
-	MPTrackNumber
-	MPDescription
-	MPRating
-	MPDate
-	MPSetting
-	MPURL
-	MPLanguage
-	MPNowPlaying
-	MPPublisher
-	MPEncodedBy
-	MPArtworkURL
-	MPTrackID
+	QTNormal QuestionType = iota
+	QTWarning
+	QTCritical
 )
 
-type MediaState uint8
+// Kind of a media slave. See Media.AddSlave().
+type SlaveType int
 
 const (
-	MSNothingSpecial MediaState = iota
-	MSOpening
-	MSBuffering
-	MSPlaying
-	MSPaused
-	MSStopped
-	MSEnded
-	MSError
+	STSubtitle SlaveType = iota
+	STAudio
+)
+
+// Encoding of generated images.
+type ImageFormat int
+
+const (
+	IFPNG ImageFormat = iota
+	IFJPEG
+)
+
+// Flags for Media.ParseContext(). Parsing is local only unless ParseNetwork
+// is set.
+type ParseFlag uint
+
+const (
+	ParseLocal    ParseFlag = 0x00 // Parse local media only.
+	ParseNetwork  ParseFlag = 0x01 // Parse network media as well.
+	FetchLocal    ParseFlag = 0x02 // Fetch meta and cover art from local sources.
+	FetchNetwork  ParseFlag = 0x04 // Fetch meta and cover art from the network.
+	ParseInteract ParseFlag = 0x08 // Allow the parser to show dialogs.
 )
 
 type MediaOption uint16
@@ -173,13 +251,28 @@ const (
 	MOUnique  MediaOption = 0x100
 )
 
-type TrackType int
+// Orientation of a video track, named after the corner where the first
+// pixel is stored.
+type VideoOrientation int
+
+const (
+	VOTopLeft     VideoOrientation = iota // Normal.
+	VOTopRight                            // Flipped horizontally.
+	VOBottomLeft                          // Flipped vertically.
+	VOBottomRight                         // Rotated 180 degrees.
+	VOLeftTop                             // Transposed.
+	VOLeftBottom                          // Rotated 90 degrees clockwise.
+	VORightTop                            // Rotated 90 degrees anti-clockwise.
+	VORightBottom                         // Anti-transposed.
+)
+
+// Projection of a video track.
+type VideoProjection int
 
 const (
-	TTUnknown TrackType = -1
-	TTAudio   TrackType = 0
-	TTVideo   TrackType = 1
-	TTText    TrackType = 2
+	VPRectangular           VideoProjection = 0
+	VPEquirectangular       VideoProjection = 1 // 360 degree video.
+	VPCubemapLayoutStandard VideoProjection = 0x100
 )
 
 type AudioDevice int8
@@ -0,0 +1,181 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+// #include "glue.h"
+//
+// static void goDialogSetContext(libvlc_dialog_id* id, uintptr_t ctx) {
+//    libvlc_dialog_set_context(id, (void*)ctx);
+// }
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// Receives dialogs raised by libvlc, e.g. when a stream requires credentials
+// or a certificate needs to be accepted. See Instance.SetDialogHandler().
+//
+// All methods are invoked from libvlc threads. Login, question and progress
+// dialogs stay open until they are answered or dismissed, which may be done
+// from any goroutine, either before the method returns or later on. The libvlc
+// thread that raised a login or question dialog waits for the answer.
+type DialogHandler interface {
+	// DisplayError shows an error message. It needs no answer.
+	DisplayError(title, text string)
+
+	// DisplayLogin asks for a username and password. Answer with
+	// LoginDialog.PostLogin() or Dismiss().
+	DisplayLogin(d *LoginDialog)
+
+	// DisplayQuestion asks a question. Answer with
+	// QuestionDialog.PostAction() or Dismiss().
+	DisplayQuestion(d *QuestionDialog)
+
+	// DisplayProgress shows the progress of a lengthy operation. Dismiss it
+	// to cancel the operation, if ProgressDialog.CancelLabel is not empty.
+	DisplayProgress(d *ProgressDialog)
+
+	// UpdateProgress is called when the position or text of a progress
+	// dialog changes.
+	UpdateProgress(d *ProgressDialog)
+
+	// Cancel is called when libvlc no longer needs an open dialog, e.g.
+	// because playback was stopped. The dialog is dismissed once Cancel
+	// returns, after which answers are rejected.
+	Cancel(d Dialog)
+}
+
+// A dialog raised by libvlc. Implemented by *LoginDialog, *QuestionDialog and
+// *ProgressDialog.
+type Dialog interface {
+	// Title returns the dialog title.
+	Title() string
+
+	// Text returns the dialog message.
+	Text() string
+
+	// Dismiss closes the dialog without answering it.
+	Dismiss() error
+}
+
+// Common dialog state.
+type dialog struct {
+	m     sync.Mutex
+	ptr   *C.libvlc_dialog_id
+	id    uintptr
+	title string
+	text  string
+}
+
+func (this *dialog) Title() string { return this.title }
+
+func (this *dialog) Text() string {
+	this.m.Lock()
+	defer this.m.Unlock()
+	return this.text
+}
+
+// attach binds the dialog to its libvlc counterpart. v is the dialog itself.
+func (this *dialog) attach(ptr *C.libvlc_dialog_id, title, text string, v Dialog) {
+	this.ptr = ptr
+	this.title, this.text = title, text
+	this.id = cbRegister(v)
+	C.goDialogSetContext(ptr, C.uintptr_t(this.id))
+}
+
+// Dismiss closes the dialog without answering it.
+func (this *dialog) Dismiss() error {
+	return this.close(func(p *C.libvlc_dialog_id) C.int {
+		return C.libvlc_dialog_dismiss(p)
+	})
+}
+
+// close answers the dialog with f, after which it can not be used again.
+func (this *dialog) close(f func(*C.libvlc_dialog_id) C.int) error {
+	this.m.Lock()
+	defer this.m.Unlock()
+
+	if this.ptr == nil {
+		return &VLCError{"Dialog is closed"}
+	}
+
+	ret := f(this.ptr)
+	this.ptr = nil
+	cbUnregister(this.id)
+
+	if ret != 0 {
+		return &VLCError{"Failed to answer dialog"}
+	}
+
+	return nil
+}
+
+// A dialog asking for credentials.
+type LoginDialog struct {
+	dialog
+	DefaultUsername string // Username to suggest, if any.
+	AskStore        bool   // Whether to offer storing the credentials.
+}
+
+// PostLogin answers the dialog with the given credentials. If store is true,
+// and the dialog asked for it, libvlc stores them for future use.
+func (this *LoginDialog) PostLogin(username, password string, store bool) error {
+	u := C.CString(username)
+	defer C.free(unsafe.Pointer(u))
+	p := C.CString(password)
+	defer C.free(unsafe.Pointer(p))
+
+	return this.close(func(d *C.libvlc_dialog_id) C.int {
+		return C.libvlc_dialog_post_login(d, u, p, C.bool(store))
+	})
+}
+
+// A dialog asking a question with up to two actions and a cancel option.
+type QuestionDialog struct {
+	dialog
+	Type        QuestionType
+	CancelLabel string // Text of the cancel button.
+	Action1     string // Text of the first action button, if any.
+	Action2     string // Text of the second action button, if any.
+}
+
+// PostAction answers the dialog with action 1 or 2. Use Dismiss() to cancel.
+func (this *QuestionDialog) PostAction(action int) error {
+	if action != 1 && action != 2 {
+		return &VLCError{"Action must be 1 or 2"}
+	}
+
+	return this.close(func(d *C.libvlc_dialog_id) C.int {
+		return C.libvlc_dialog_post_action(d, C.int(action))
+	})
+}
+
+// A dialog showing the progress of an operation.
+type ProgressDialog struct {
+	dialog
+	Indeterminate bool   // The position is meaningless.
+	CancelLabel   string // Text of the cancel button. Empty if not cancellable.
+	position      float32
+}
+
+// Position returns the progress between 0.0 and 1.0.
+func (this *ProgressDialog) Position() float32 {
+	this.m.Lock()
+	defer this.m.Unlock()
+	return this.position
+}
+
+// update records a progress update.
+func (this *ProgressDialog) update(position float32, text string) {
+	this.m.Lock()
+	this.position, this.text = position, text
+	this.m.Unlock()
+}
+
+// Used in Instance.SetDialogHandler() to dispatch dialogs.
+type dialogReq struct {
+	h DialogHandler
+}
@@ -0,0 +1,152 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+// #include "glue.h"
+import "C"
+import (
+	"fmt"
+)
+
+// A built-in equalizer preset.
+type EqualizerPreset struct {
+	Index int    // Pass to NewEqualizerFromPreset().
+	Name  string // Display name, e.g. "Rock".
+}
+
+// EqualizerPresets returns the equalizer presets built into libvlc.
+func EqualizerPresets() []EqualizerPreset {
+	n := int(C.libvlc_audio_equalizer_get_preset_count())
+	list := make([]EqualizerPreset, 0, n)
+
+	for i := 0; i < n; i++ {
+		if c := C.libvlc_audio_equalizer_get_preset_name(C.uint(i)); c != nil {
+			list = append(list, EqualizerPreset{i, C.GoString(c)})
+		}
+	}
+
+	return list
+}
+
+// EqualizerBands returns the center frequency in Hz of each equalizer band.
+// The band indices used by Equalizer.Amp() and Equalizer.SetAmp() refer to
+// this list.
+func EqualizerBands() []float32 {
+	n := int(C.libvlc_audio_equalizer_get_band_count())
+	list := make([]float32, n)
+
+	for i := range list {
+		list[i] = float32(C.libvlc_audio_equalizer_get_band_frequency(C.uint(i)))
+	}
+
+	return list
+}
+
+// An audio equalizer. Apply it to a player with Player.SetEqualizer().
+//
+// Amplification values are in dB, and are clamped by libvlc to the range
+// -20.0 to 20.0.
+type Equalizer struct {
+	ptr *C.libvlc_equalizer_t
+	ref ref
+}
+
+// newEqualizer wraps an equalizer owned by the caller.
+func newEqualizer(c *C.libvlc_equalizer_t) *Equalizer {
+	eq := &Equalizer{ptr: c}
+	eq.ref.own(eq, "Equalizer")
+	return eq
+}
+
+// NewEqualizer creates an equalizer with all bands and the preamp set to 0.0.
+func NewEqualizer() (*Equalizer, error) {
+	if c := C.libvlc_audio_equalizer_new(); c != nil {
+		return newEqualizer(c), nil
+	}
+
+	return nil, checkError()
+}
+
+// NewEqualizerFromPreset creates an equalizer initialized from the preset
+// with the given index. See EqualizerPresets().
+func NewEqualizerFromPreset(index int) (*Equalizer, error) {
+	if index < 0 {
+		return nil, &VLCError{fmt.Sprintf("Invalid equalizer preset: %d", index)}
+	}
+
+	if c := C.libvlc_audio_equalizer_new_from_preset(C.uint(index)); c != nil {
+		return newEqualizer(c), nil
+	}
+
+	return nil, &VLCError{fmt.Sprintf("Invalid equalizer preset: %d", index)}
+}
+
+// Release destroys the equalizer. Players it was applied to are not
+// affected, as they keep their own copy of the settings.
+func (this *Equalizer) Release() (err error) {
+	if this.ptr == nil {
+		return &VLCError{"Equalizer is nil"}
+	}
+
+	return this.Close()
+}
+
+// Close destroys the equalizer, like Release, but does nothing if it has
+// already been destroyed.
+func (this *Equalizer) Close() error {
+	if this.ptr != nil {
+		this.ref.close(this, func() { C.libvlc_audio_equalizer_release(this.ptr) })
+		this.ptr = nil
+	}
+	return nil
+}
+
+// Preamp returns the pre-amplification value in dB.
+func (this *Equalizer) Preamp() (float32, error) {
+	if this.ptr == nil {
+		return 0, &VLCError{"Equalizer is nil"}
+	}
+
+	return float32(C.libvlc_audio_equalizer_get_preamp(this.ptr)), nil
+}
+
+// SetPreamp sets the pre-amplification value in dB.
+func (this *Equalizer) SetPreamp(v float32) error {
+	if this.ptr == nil {
+		return &VLCError{"Equalizer is nil"}
+	}
+
+	if C.libvlc_audio_equalizer_set_preamp(this.ptr, C.float(v)) != 0 {
+		return &VLCError{fmt.Sprintf("Invalid preamp value: %g", v)}
+	}
+
+	return nil
+}
+
+// Amp returns the amplification value in dB of the given band.
+func (this *Equalizer) Amp(band int) (float32, error) {
+	if this.ptr == nil {
+		return 0, &VLCError{"Equalizer is nil"}
+	}
+
+	if band < 0 || band >= int(C.libvlc_audio_equalizer_get_band_count()) {
+		return 0, &VLCError{fmt.Sprintf("Invalid equalizer band: %d", band)}
+	}
+
+	return float32(C.libvlc_audio_equalizer_get_amp_at_index(this.ptr, C.uint(band))), nil
+}
+
+// SetAmp sets the amplification value in dB of the given band.
+func (this *Equalizer) SetAmp(band int, v float32) error {
+	if this.ptr == nil {
+		return &VLCError{"Equalizer is nil"}
+	}
+
+	if band < 0 || C.libvlc_audio_equalizer_set_amp_at_index(this.ptr, C.float(v), C.uint(band)) != 0 {
+		return &VLCError{fmt.Sprintf("Invalid equalizer band: %d", band)}
+	}
+
+	return nil
+}
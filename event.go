@@ -5,170 +5,217 @@
 package vlc
 
 // #include "glue.h"
+//
+// static libvlc_media_t* goEventMedia(const libvlc_event_t* e) {
+//    switch (e->type) {
+//    case libvlc_MediaSubItemAdded:           return e->u.media_subitem_added.new_child;
+//    case libvlc_MediaFreed:                  return e->u.media_freed.md;
+//    case libvlc_MediaSubItemTreeAdded:       return e->u.media_subitemtree_added.item;
+//    case libvlc_MediaPlayerMediaChanged:     return e->u.media_player_media_changed.new_media;
+//    case libvlc_MediaListItemAdded:
+//    case libvlc_MediaListViewItemAdded:      return e->u.media_list_item_added.item;
+//    case libvlc_MediaListWillAddItem:
+//    case libvlc_MediaListViewWillAddItem:    return e->u.media_list_will_add_item.item;
+//    case libvlc_MediaListItemDeleted:
+//    case libvlc_MediaListViewItemDeleted:    return e->u.media_list_item_deleted.item;
+//    case libvlc_MediaListWillDeleteItem:
+//    case libvlc_MediaListViewWillDeleteItem: return e->u.media_list_will_delete_item.item;
+//    case libvlc_MediaListPlayerNextItemSet:  return e->u.media_list_player_next_item_set.item;
+//    }
+//    return NULL;
+// }
+//
+// static int goEventInt(const libvlc_event_t* e) {
+//    switch (e->type) {
+//    case libvlc_MediaMetaChanged:            return e->u.media_meta_changed.meta_type;
+//    case libvlc_MediaParsedChanged:          return e->u.media_parsed_changed.new_status;
+//    case libvlc_MediaStateChanged:           return e->u.media_state_changed.new_state;
+//    case libvlc_MediaPlayerTitleChanged:     return e->u.media_player_title_changed.new_title;
+//    case libvlc_MediaPlayerChapterChanged:   return e->u.media_player_chapter_changed.new_chapter;
+//    case libvlc_MediaPlayerSeekableChanged:  return e->u.media_player_seekable_changed.new_seekable;
+//    case libvlc_MediaPlayerPausableChanged:  return e->u.media_player_pausable_changed.new_pausable;
+//    case libvlc_MediaPlayerScrambledChanged: return e->u.media_player_scrambled_changed.new_scrambled;
+//    case libvlc_MediaPlayerVout:             return e->u.media_player_vout.new_count;
+//    case libvlc_MediaPlayerESAdded:
+//    case libvlc_MediaPlayerESDeleted:
+//    case libvlc_MediaPlayerESSelected:       return e->u.media_player_es_changed.i_id;
+//    case libvlc_MediaListItemAdded:
+//    case libvlc_MediaListViewItemAdded:      return e->u.media_list_item_added.index;
+//    case libvlc_MediaListWillAddItem:
+//    case libvlc_MediaListViewWillAddItem:    return e->u.media_list_will_add_item.index;
+//    case libvlc_MediaListItemDeleted:
+//    case libvlc_MediaListViewItemDeleted:    return e->u.media_list_item_deleted.index;
+//    case libvlc_MediaListWillDeleteItem:
+//    case libvlc_MediaListViewWillDeleteItem: return e->u.media_list_will_delete_item.index;
+//    }
+//    return 0;
+// }
+//
+// static int goEventTrackType(const libvlc_event_t* e) {
+//    return e->u.media_player_es_changed.i_type;
+// }
+//
+// static int64_t goEventTime(const libvlc_event_t* e) {
+//    switch (e->type) {
+//    case libvlc_MediaDurationChanged:      return e->u.media_duration_changed.new_duration;
+//    case libvlc_MediaPlayerTimeChanged:    return e->u.media_player_time_changed.new_time;
+//    case libvlc_MediaPlayerLengthChanged:  return e->u.media_player_length_changed.new_length;
+//    }
+//    return 0;
+// }
+//
+// static float goEventFloat(const libvlc_event_t* e) {
+//    switch (e->type) {
+//    case libvlc_MediaPlayerBuffering:       return e->u.media_player_buffering.new_cache;
+//    case libvlc_MediaPlayerPositionChanged: return e->u.media_player_position_changed.new_position;
+//    case libvlc_MediaPlayerAudioVolume:     return e->u.media_player_audio_volume.volume;
+//    }
+//    return 0;
+// }
+//
+// static const char* goEventString(const libvlc_event_t* e, int n) {
+//    switch (e->type) {
+//    case libvlc_MediaPlayerSnapshotTaken: return e->u.media_player_snapshot_taken.psz_filename;
+//    case libvlc_MediaPlayerAudioDevice:   return e->u.media_player_audio_device.device;
+//    }
+//    if (e->type >= libvlc_VlmMediaAdded && e->type <= libvlc_VlmMediaInstanceStatusError) {
+//        return n == 0 ? e->u.vlm_media_event.psz_media_name : e->u.vlm_media_event.psz_instance_name;
+//    }
+//    return NULL;
+// }
 import "C"
 import (
-	"bytes"
-	"encoding/binary"
-	"unsafe"
-)
-
-// Generic event type. Use a switch on Event.Type to determine which method
-// to call for its data. The method names are the same as the event type.
-// It's a bit of an odd way to handle this, but since event data comes in the
-// form of a C union, there is some binary magic that has to be performed based
-// on the event type.
-type Event struct {
-	Type EventType
-	b    bytes.Buffer
-}
-
-func (this *Event) MediaMetaChanged() MetaProperty                    { return MetaProperty(this.readU32()) }
-func (this *Event) MediaSubItemAdded() *Media                         { return this.readMedia() }
-func (this *Event) MediaDurationChanged() int64                       { return this.readI64() }
-func (this *Event) MediaParsedChanged() int                           { return int(this.readI32()) }
-func (this *Event) MediaFreed() *Media                                { return this.readMedia() }
-func (this *Event) MediaStateChanged() MediaState                     { return MediaState(this.readU32()) }
-func (this *Event) MediaPlayerTimeChanged() int64                     { return this.readI64() }
-func (this *Event) MediaPlayerPositionChanged() float32               { return this.readF32() }
-func (this *Event) MediaPlayerSeekableChanged() bool                  { return this.readB() }
-func (this *Event) MediaPlayerTitleChanged() bool                     { return this.readB() }
-func (this *Event) MediaPlayerLengthChanged() bool                    { return this.readB() }
-func (this *Event) MediaPlayerSnapshotTaken() string                  { return this.readS() }
-func (this *Event) MediaListItemAdded() *Media                        { return this.readMedia() }
-func (this *Event) MediaListWillAddItem() *Media                      { return this.readMedia() }
-func (this *Event) MediaListItemDeleted() *Media                      { return this.readMedia() }
-func (this *Event) MediaListWillDeleteItem() *Media                   { return this.readMedia() }
-func (this *Event) MediaListPlayerNextItemSet() *Media                { return this.readMedia() }
-func (this *Event) VlmMediaAdded() (string, string)                   { return this.readS2() }
-func (this *Event) VlmMediaRemoved() (string, string)                 { return this.readS2() }
-func (this *Event) VlmMediaChanged() (string, string)                 { return this.readS2() }
-func (this *Event) VlmMediaInstanceStarted() (string, string)         { return this.readS2() }
-func (this *Event) VlmMediaInstanceStopped() (string, string)         { return this.readS2() }
-func (this *Event) VlmMediaInstanceStatusInitAdded() (string, string) { return this.readS2() }
-func (this *Event) VlmMediaInstanceStatusOpening() (string, string)   { return this.readS2() }
-func (this *Event) VlmMediaInstanceStatusPlaying() (string, string)   { return this.readS2() }
-func (this *Event) VlmMediaInstanceStatusPause() (string, string)     { return this.readS2() }
-func (this *Event) VlmMediaInstanceStatusEnd() (string, string)       { return this.readS2() }
-func (this *Event) VlmMediaInstanceStatusError() (string, string)     { return this.readS2() }
-func (this *Event) MediaPlayerMediaChanged() *Media                   { return this.readMedia() }
-func (this *Event) MediaPlayerNothingSpecial() MediaState             { return MediaState(this.readU32()) }
-func (this *Event) MediaPlayerOpening() MediaState                    { return MediaState(this.readU32()) }
-func (this *Event) MediaPlayerBuffering() MediaState                  { return MediaState(this.readU32()) }
-func (this *Event) MediaPlayerPlaying() MediaState                    { return MediaState(this.readU32()) }
-func (this *Event) MediaPlayerPaused() MediaState                     { return MediaState(this.readU32()) }
-func (this *Event) MediaPlayerStopped() MediaState                    { return MediaState(this.readU32()) }
-func (this *Event) MediaPlayerForward() MediaState                    { return MediaState(this.readU32()) }
-func (this *Event) MediaPlayerBackward() MediaState                   { return MediaState(this.readU32()) }
-func (this *Event) MediaPlayerEndReached() MediaState                 { return MediaState(this.readU32()) }
-func (this *Event) MediaPlayerEncounteredError() MediaState           { return MediaState(this.readU32()) }
-func (this *Event) MediaListPlayerPlayed() MediaState                 { return MediaState(this.readU32()) }
-func (this *Event) MediaListPlayerStopped() MediaState                { return MediaState(this.readU32()) }
-func (this *Event) MediaListViewItemAdded() *Media                    { return this.readMedia() }
-func (this *Event) MediaListViewWillAddItem() *Media                  { return this.readMedia() }
-func (this *Event) MediaListViewItemDeleted() *Media                  { return this.readMedia() }
-func (this *Event) MediaListViewWillDeleteItem() *Media               { return this.readMedia() }
-func (this *Event) MediaDiscovererStarted() *Discoverer               { return this.readDiscoverer() }
-func (this *Event) MediaDiscovererEnded() *Discoverer                 { return this.readDiscoverer() }
-
-func (this *Event) readI8() int8 {
-	var i int8
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i
-}
-
-func (this *Event) readI16() int16 {
-	var i int16
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i
-}
-
-func (this *Event) readI32() int32 {
-	var i int32
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i
-}
-
-func (this *Event) readI64() int64 {
-	var i int64
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i
-}
+	"time"
 
-func (this *Event) readU8() uint8 {
-	var i uint8
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i
-}
-func (this *Event) readU16() uint16 {
-	var i uint16
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i
-}
-
-func (this *Event) readU32() uint32 {
-	var i uint32
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i
-}
-
-func (this *Event) readU64() uint64 {
-	var i uint64
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i
-}
-
-func (this *Event) readF32() float32 {
-	var i float32
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i
-}
-
-func (this *Event) readF64() float64 {
-	var i float64
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i
-}
-
-func (this *Event) readS() string {
-	var i uint64
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	up := unsafe.Pointer(uintptr(i))
-	s := C.GoString((*C.char)(up))
-	C.free(up)
-	return s
-}
-
-func (this *Event) readS2() (string, string) {
-	var a, b uint64
-
-	binary.Read(&this.b, binary.LittleEndian, &a)
-	binary.Read(&this.b, binary.LittleEndian, &b)
-
-	pa := unsafe.Pointer(uintptr(a))
-	pb := unsafe.Pointer(uintptr(b))
-
-	sa := C.GoString((*C.char)(pa))
-	sb := C.GoString((*C.char)(pb))
-
-	C.free(pa)
-	C.free(pb)
-	return sa, sb
-}
-
-func (this *Event) readB() bool {
-	var i int64
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return i == 0
-}
+	"github.com/jteeuwen/go-vlc/vlcapi"
+)
 
-func (this *Event) readMedia() *Media {
-	var i uint64
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return &Media{(*C.libvlc_media_t)(unsafe.Pointer(uintptr(i)))}
-}
+// The event types below are defined in package vlcapi. See there for their
+// documentation.
+type (
+	Event                 = vlcapi.Event
+	MetaChangedEvent      = vlcapi.MetaChangedEvent
+	SubItemAddedEvent     = vlcapi.SubItemAddedEvent
+	DurationChangedEvent  = vlcapi.DurationChangedEvent
+	ParsedChangedEvent    = vlcapi.ParsedChangedEvent
+	MediaFreedEvent       = vlcapi.MediaFreedEvent
+	StateChangedEvent     = vlcapi.StateChangedEvent
+	MediaChangedEvent     = vlcapi.MediaChangedEvent
+	BufferingEvent        = vlcapi.BufferingEvent
+	TimeChangedEvent      = vlcapi.TimeChangedEvent
+	PositionChangedEvent  = vlcapi.PositionChangedEvent
+	SeekableChangedEvent  = vlcapi.SeekableChangedEvent
+	PausableChangedEvent  = vlcapi.PausableChangedEvent
+	ScrambledChangedEvent = vlcapi.ScrambledChangedEvent
+	TitleChangedEvent     = vlcapi.TitleChangedEvent
+	ChapterChangedEvent   = vlcapi.ChapterChangedEvent
+	SnapshotTakenEvent    = vlcapi.SnapshotTakenEvent
+	LengthChangedEvent    = vlcapi.LengthChangedEvent
+	VoutEvent             = vlcapi.VoutEvent
+	ESChangedEvent        = vlcapi.ESChangedEvent
+	AudioVolumeEvent      = vlcapi.AudioVolumeEvent
+	AudioDeviceEvent      = vlcapi.AudioDeviceEvent
+	ListItemEvent         = vlcapi.ListItemEvent
+	NextItemSetEvent      = vlcapi.NextItemSetEvent
+	VlmMediaEvent         = vlcapi.VlmMediaEvent
+)
 
-func (this *Event) readDiscoverer() *Discoverer {
-	var i uint64
-	binary.Read(&this.b, binary.LittleEndian, &i)
-	return &Discoverer{(*C.libvlc_media_discoverer_t)(unsafe.Pointer(uintptr(i)))}
+// Maps the MediaPlayer state events onto the state they announce.
+var playerStates = map[EventType]MediaState{
+	MediaPlayerNothingSpecial:   MSNothingSpecial,
+	MediaPlayerOpening:          MSOpening,
+	MediaPlayerPlaying:          MSPlaying,
+	MediaPlayerPaused:           MSPaused,
+	MediaPlayerStopped:          MSStopped,
+	MediaPlayerEndReached:       MSEnded,
+	MediaPlayerEncounteredError: MSError,
+}
+
+// newEvent decodes the libvlc event e. All data is copied, except for
+// Media references.
+func newEvent(e *C.libvlc_event_t) *Event {
+	t := EventType(e._type)
+	var payload interface{}
+
+	switch t {
+	case MediaMetaChanged:
+		payload = &MetaChangedEvent{MetaProperty(C.goEventInt(e))}
+	case MediaSubItemAdded, MediaSubItemTreeAdded:
+		payload = &SubItemAddedEvent{payloadMedia(e)}
+	case MediaDurationChanged:
+		payload = &DurationChangedEvent{time.Duration(C.goEventTime(e)) * time.Millisecond}
+	case MediaParsedChanged:
+		payload = &ParsedChangedEvent{ParsedStatus(C.goEventInt(e))}
+	case MediaFreed:
+		payload = &MediaFreedEvent{payloadMedia(e)}
+	case MediaStateChanged:
+		payload = &StateChangedEvent{MediaState(C.goEventInt(e))}
+
+	case MediaPlayerMediaChanged:
+		payload = &MediaChangedEvent{payloadMedia(e)}
+	case MediaPlayerNothingSpecial, MediaPlayerOpening, MediaPlayerPlaying,
+		MediaPlayerPaused, MediaPlayerStopped, MediaPlayerEndReached,
+		MediaPlayerEncounteredError:
+		payload = &StateChangedEvent{playerStates[t]}
+	case MediaPlayerBuffering:
+		payload = &BufferingEvent{float32(C.goEventFloat(e))}
+	case MediaPlayerTimeChanged:
+		payload = &TimeChangedEvent{time.Duration(C.goEventTime(e)) * time.Millisecond}
+	case MediaPlayerPositionChanged:
+		payload = &PositionChangedEvent{float32(C.goEventFloat(e))}
+	case MediaPlayerSeekableChanged:
+		payload = &SeekableChangedEvent{C.goEventInt(e) != 0}
+	case MediaPlayerPausableChanged:
+		payload = &PausableChangedEvent{C.goEventInt(e) != 0}
+	case MediaPlayerScrambledChanged:
+		payload = &ScrambledChangedEvent{C.goEventInt(e) != 0}
+	case MediaPlayerTitleChanged:
+		payload = &TitleChangedEvent{int(C.goEventInt(e))}
+	case MediaPlayerChapterChanged:
+		payload = &ChapterChangedEvent{int(C.goEventInt(e))}
+	case MediaPlayerSnapshotTaken:
+		payload = &SnapshotTakenEvent{C.GoString(C.goEventString(e, 0))}
+	case MediaPlayerLengthChanged:
+		payload = &LengthChangedEvent{time.Duration(C.goEventTime(e)) * time.Millisecond}
+	case MediaPlayerVout:
+		payload = &VoutEvent{int(C.goEventInt(e))}
+	case MediaPlayerESAdded, MediaPlayerESDeleted, MediaPlayerESSelected:
+		payload = &ESChangedEvent{TrackType(C.goEventTrackType(e)), int(C.goEventInt(e))}
+	case MediaPlayerAudioVolume:
+		payload = &AudioVolumeEvent{float32(C.goEventFloat(e))}
+	case MediaPlayerAudioDevice:
+		payload = &AudioDeviceEvent{C.GoString(C.goEventString(e, 0))}
+
+	case MediaListItemAdded, MediaListWillAddItem, MediaListItemDeleted,
+		MediaListWillDeleteItem, MediaListViewItemAdded, MediaListViewWillAddItem,
+		MediaListViewItemDeleted, MediaListViewWillDeleteItem:
+		payload = &ListItemEvent{payloadMedia(e), int(C.goEventInt(e))}
+	case MediaListPlayerNextItemSet:
+		payload = &NextItemSetEvent{payloadMedia(e)}
+
+	case VlmMediaAdded, VlmMediaRemoved, VlmMediaChanged, VlmMediaInstanceStarted,
+		VlmMediaInstanceStopped, VlmMediaInstanceStatusInit, VlmMediaInstanceStatusOpening,
+		VlmMediaInstanceStatusPlaying, VlmMediaInstanceStatusPause, VlmMediaInstanceStatusEnd,
+		VlmMediaInstanceStatusError:
+		payload = &VlmMediaEvent{C.GoString(C.goEventString(e, 0)), C.GoString(C.goEventString(e, 1))}
+	}
+
+	return vlcapi.NewEvent(t, payload)
+}
+
+// eventMedia returns the media referenced by e, or nil if there is none.
+func eventMedia(e *C.libvlc_event_t) *Media {
+	if c := C.goEventMedia(e); c != nil {
+		return &Media{ptr: c}
+	}
+	return nil
+}
+
+// payloadMedia returns the media referenced by e for an event payload, which
+// holds a nil MediaAPI if there is none.
+func payloadMedia(e *C.libvlc_event_t) MediaAPI {
+	if m := eventMedia(e); m != nil {
+		return m
+	}
+	return nil
 }
@@ -8,18 +8,22 @@ package vlc
 //
 // extern void goEventCB(const struct libvlc_event_t*, void*);
 //
-// static int goAttach(libvlc_event_manager_t* em, libvlc_event_type_t et, void* userdata) {
-//    return libvlc_event_attach(em, et, goEventCB, userdata);
+// static int goAttach(libvlc_event_manager_t* em, libvlc_event_type_t et, uintptr_t userdata) {
+//    return libvlc_event_attach(em, et, goEventCB, (void*)userdata);
 // }
-// static void goDetach(libvlc_event_manager_t* em, libvlc_event_type_t et, void* userdata) {
-//    libvlc_event_detach(em, et, goEventCB, userdata);
+// static void goDetach(libvlc_event_manager_t* em, libvlc_event_type_t et, uintptr_t userdata) {
+//    libvlc_event_detach(em, et, goEventCB, (void*)userdata);
 // }
 import "C"
 import (
 	"sync"
-	"unsafe"
+
+	"github.com/jteeuwen/go-vlc/vlcapi"
 )
 
+// Number of events buffered by a channel returned from EventManager.Subscribe().
+const EventBufferSize = vlcapi.EventBufferSize
+
 // A libvlc instance has an event manager which can be used to hook event callbacks,
 type EventManager struct {
 	ptr    *C.libvlc_event_manager_t
@@ -42,14 +46,24 @@ func (this *EventManager) Attach(et EventType, cb EventHandler, userdata interfa
 		return 0, &VLCError{"EventManager is nil"}
 	}
 
-	id = this.getUniqId()
+	ed := &eventData{C.libvlc_event_type_t(et), cb, userdata, 0}
+	ed.id = cbRegister(ed)
 
 	this.m.Lock()
-	this.events[id] = &eventData{C.libvlc_event_type_t(et), cb, userdata}
+	id = this.getUniqId()
+	this.events[id] = ed
 	this.m.Unlock()
 
-	if C.goAttach(this.ptr, this.events[id].t, unsafe.Pointer(this.events[id])) != 0 {
-		err = checkError()
+	if C.goAttach(this.ptr, ed.t, C.uintptr_t(ed.id)) != 0 {
+		this.m.Lock()
+		delete(this.events, id)
+		this.m.Unlock()
+		cbUnregister(ed.id)
+
+		if err = checkError(); err == nil {
+			err = &VLCError{"Failed to attach event handler"}
+		}
+		return 0, err
 	}
 
 	return
@@ -73,18 +87,132 @@ func (this *EventManager) Detach(id int) (err error) {
 	delete(this.events, id)
 	this.m.Unlock()
 
-	C.goDetach(this.ptr, ed.t, unsafe.Pointer(ed))
+	C.goDetach(this.ptr, ed.t, C.uintptr_t(ed.id))
+	cbUnregister(ed.id)
 	return
 }
 
-// getUniqId finds and returns a unique event id.
-func (this *EventManager) getUniqId() int {
-	var id int
-	var ok bool
+// Subscribe returns a channel on which events of the given types are
+// delivered, along with a function which cancels the subscription.
+//
+// Unlike Attach, events are consumed on a goroutine of your choosing, so it is
+// safe to call back into the object which emitted them. The channel buffers up
+// to EventBufferSize events. If the buffer is full when a new event arrives,
+// the oldest buffered event is dropped to make room for it; libvlc is never
+// blocked by a slow consumer.
+//
+// Media in the payloads of queued events are retained, so they stay valid
+// after libvlc has moved on. The reader must call Release() on each of them,
+// see Event.Media(). MediaFreed events carry no media, as it is destroyed by
+// the time they are read.
+//
+// Calling cancel detaches all handlers, closes the channel and releases the
+// events still buffered in it. It may be called more than once, but not from
+// inside an EventHandler.
+func (this *EventManager) Subscribe(types ...EventType) (events <-chan *Event, cancel func(), err error) {
+	if this.ptr == nil {
+		return nil, nil, &VLCError{"EventManager is nil"}
+	}
+
+	sub := &subscription{c: make(chan *Event, EventBufferSize)}
+	ids := make([]int, 0, len(types))
+
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			for _, id := range ids {
+				this.Detach(id)
+			}
+
+			sub.close()
+		})
+	}
+
+	for _, et := range types {
+		var id int
+		if id, err = this.Attach(et, sub.send, nil); err != nil {
+			cancel()
+			return nil, nil, err
+		}
 
+		ids = append(ids, id)
+	}
+
+	return sub.c, cancel, nil
+}
+
+// Channel state for EventManager.Subscribe().
+type subscription struct {
+	m      sync.Mutex
+	c      chan *Event
+	closed bool
+}
+
+// send delivers evt without blocking, dropping the oldest buffered event
+// if the channel is full.
+func (this *subscription) send(evt *Event, userdata interface{}) {
 	this.m.Lock()
 	defer this.m.Unlock()
 
+	if this.closed {
+		return
+	}
+
+	evt = queuedEvent(evt)
+
+	for {
+		select {
+		case this.c <- evt:
+			return
+		default:
+		}
+
+		select {
+		case old := <-this.c:
+			releaseEvent(old)
+		default:
+		}
+	}
+}
+
+// close closes the channel and releases the events left in it.
+func (this *subscription) close() {
+	this.m.Lock()
+	this.closed = true
+	close(this.c)
+	this.m.Unlock()
+
+	for evt := range this.c {
+		releaseEvent(evt)
+	}
+}
+
+// queuedEvent prepares evt for delivery after the event handler returns: its
+// media is retained, or dropped if it is being freed.
+func queuedEvent(evt *Event) *Event {
+	if _, ok := evt.Payload().(*MediaFreedEvent); ok {
+		return vlcapi.NewEvent(evt.Type, &MediaFreedEvent{})
+	}
+
+	if m, ok := evt.Media().(*Media); ok {
+		m.Retain()
+	}
+	return evt
+}
+
+// releaseEvent releases the media of an event which will not be read.
+func releaseEvent(evt *Event) {
+	if m, ok := evt.Media().(*Media); ok {
+		m.Release()
+	}
+}
+
+// getUniqId finds and returns a unique event id. The caller must hold this.m
+// until the id is in use.
+func (this *EventManager) getUniqId() int {
+	var id int
+	var ok bool
+
 	for {
 		if _, ok = this.events[id]; !ok {
 			break
@@ -0,0 +1,35 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import "testing"
+
+func TestSubscriptionSend(t *testing.T) {
+	sub := &subscription{c: make(chan *Event, 2)}
+
+	events := make([]*Event, 4)
+	for i := range events {
+		events[i] = &Event{Type: MediaPlayerTimeChanged}
+		sub.send(events[i], nil)
+	}
+
+	// The channel holds the newest events; older ones were dropped.
+	for _, want := range events[2:] {
+		if have := <-sub.c; have != want {
+			t.Fatalf("event: have %p, want %p", have, want)
+		}
+	}
+
+	if n := len(sub.c); n != 0 {
+		t.Fatalf("buffered events: have %d, want 0", n)
+	}
+
+	sub.close()
+	sub.send(events[0], nil)
+
+	if _, ok := <-sub.c; ok {
+		t.Fatalf("send after close delivered an event")
+	}
+}
@@ -9,6 +9,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	vlc "github.com/jteeuwen/go-vlc"
 	"os"
@@ -62,11 +63,20 @@ func main() {
 	// This is just to demonstrate usage of event callbacks.
 	evt.Attach(vlc.MediaPlayerStopped, handler, "wahey!")
 
-	// Play the video.
-	player.Play()
-
 	// Give the player 10 seconds of play time.
-	time.Sleep(1e10)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Play the video, and wait until it has actually started.
+	if err = player.PlayAndWait(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "[e] PlayAndWait(): %v", err)
+		return
+	}
+
+	// Wait for the end of the video, or the timeout.
+	if _, err = player.WaitForState(ctx, vlc.MSEnded); err != nil && err != context.DeadlineExceeded {
+		fmt.Fprintf(os.Stderr, "[e] WaitForState(): %v", err)
+	}
 
 	// Stop playing.
 	player.Stop()
@@ -0,0 +1,325 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+// #include "glue.h"
+//
+// extern void goFrameTimeCB(const struct libvlc_event_t*, void*);
+//
+// static void goUnsetFrameSink(libvlc_media_player_t* mp, uintptr_t userdata) {
+//    libvlc_event_detach(libvlc_media_player_event_manager(mp), libvlc_MediaPlayerTimeChanged,
+//                        goFrameTimeCB, (void*)userdata);
+// }
+import "C"
+import (
+	"image"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Alignment of plane addresses and line pitches handed to libvlc.
+const planeAlign = 32
+
+// A single decoded video frame delivered by a FrameSink.
+//
+// Call Frame.Release() once you are done with the image, so its memory can be
+// reused for subsequent frames.
+type Frame struct {
+	Image image.Image   // Either an *image.RGBA or an *image.YCbCr.
+	Time  time.Duration // Approximate player time at which the frame was displayed.
+	sink  *FrameSink
+}
+
+// Release returns the frame's image to the sink's pool. The frame must not
+// be used afterwards.
+func (this *Frame) Release() {
+	if this.sink != nil && this.Image != nil {
+		this.sink.put(this.Image)
+	}
+
+	this.sink = nil
+	this.Image = nil
+}
+
+// A FrameSink receives decoded video from a Player and delivers it as Go
+// images on channel C. Create one with Player.FrameSink().
+//
+// The sink allocates and aligns the pixel planes libvlc decodes into, and
+// copies each displayed frame into an image taken from a reusable pool. If C
+// is full when a frame is displayed, that frame is dropped.
+type FrameSink struct {
+	C <-chan *Frame
+
+	m      sync.Mutex
+	c      chan *Frame
+	pool   chan image.Image
+	id     uintptr
+	mp     *C.libvlc_media_player_t // Player reference held until Close.
+	closed bool
+
+	// Last player time reported by libvlc, and when it was reported.
+	// libvlc can not be queried from inside the video callbacks.
+	time   time.Duration
+	timeAt time.Time
+
+	// Requested output format. Zero dimensions select the source size.
+	chroma        string
+	width, height uint
+
+	// Current plane geometry, set up by libvlc's format callback.
+	geom frameGeometry
+}
+
+// Plane layout of the decoded pictures.
+type frameGeometry struct {
+	chroma  string
+	width   int
+	height  int
+	pitches [3]int
+	lines   [3]int
+	planes  [3]unsafe.Pointer // Aligned plane addresses.
+	bases   [3]unsafe.Pointer // Allocated memory backing planes.
+}
+
+func newFrameSink(buffer int) *FrameSink {
+	if buffer < 1 {
+		buffer = 1
+	}
+
+	c := make(chan *Frame, buffer)
+	return &FrameSink{
+		C:      c,
+		c:      c,
+		pool:   make(chan image.Image, buffer+2),
+		chroma: "RV32",
+	}
+}
+
+// SetFormat sets the chroma and dimensions frames are decoded to. The chroma
+// should be one of "RV32" or "RV24", which yield *image.RGBA frames, or
+// "I420", which yields *image.YCbCr frames. Zero dimensions select the size
+// of the source video. The default is "RV32" at the source size.
+//
+// Changes take effect when libvlc next sets up its video output, e.g. on the
+// next call to Player.Play().
+func (this *FrameSink) SetFormat(chroma string, width, height uint) error {
+	switch chroma {
+	case "RV32", "RV24", "I420":
+	default:
+		return &VLCError{"Unsupported chroma: " + chroma}
+	}
+
+	this.m.Lock()
+	this.chroma = chroma
+	this.width, this.height = width, height
+	this.m.Unlock()
+	return nil
+}
+
+// Close stops frame delivery, closes channel C and releases the sink's
+// reference to its player. Frames which were already received remain valid.
+// The sink should not be closed while the player is still playing, as libvlc
+// keeps decoding into its planes until playback stops.
+func (this *FrameSink) Close() {
+	this.m.Lock()
+	defer this.m.Unlock()
+
+	if this.closed {
+		return
+	}
+
+	this.closed = true
+	close(this.c)
+
+	if this.mp != nil {
+		C.goUnsetFrameSink(this.mp, C.uintptr_t(this.id))
+		C.libvlc_media_player_release(this.mp)
+		this.mp = nil
+	}
+
+	// Without an active video output, libvlc will not call cleanup for us.
+	if this.geom.bases[0] == nil {
+		cbUnregister(this.id)
+	}
+}
+
+// setup computes the plane geometry for the given source size and allocates
+// the planes. The returned geometry tells libvlc what to decode to.
+func (this *FrameSink) setup(width, height int) frameGeometry {
+	this.m.Lock()
+	defer this.m.Unlock()
+
+	this.free()
+
+	g := frameGeometry{chroma: this.chroma, width: width, height: height}
+	if this.width > 0 && this.height > 0 {
+		g.width, g.height = int(this.width), int(this.height)
+	}
+
+	switch g.chroma {
+	case "RV32":
+		g.pitches[0], g.lines[0] = alignUp(g.width*4), g.height
+	case "RV24":
+		g.pitches[0], g.lines[0] = alignUp(g.width*3), g.height
+	case "I420":
+		cw, ch := (g.width+1)/2, (g.height+1)/2
+		g.pitches = [3]int{alignUp(g.width), alignUp(cw), alignUp(cw)}
+		g.lines = [3]int{g.height, ch, ch}
+	}
+
+	for i := range g.planes {
+		if g.lines[i] == 0 {
+			continue
+		}
+
+		size := g.pitches[i] * g.lines[i]
+		g.bases[i] = C.malloc(C.size_t(size + planeAlign))
+		g.planes[i] = unsafe.Add(g.bases[i], alignUp(int(uintptr(g.bases[i])))-int(uintptr(g.bases[i])))
+	}
+
+	this.geom = g
+	return g
+}
+
+// free releases the current planes. The caller must hold the lock.
+func (this *FrameSink) free() {
+	for i, p := range this.geom.bases {
+		if p != nil {
+			C.free(p)
+		}
+		this.geom.bases[i] = nil
+		this.geom.planes[i] = nil
+	}
+}
+
+// cleanup is invoked when libvlc tears down its video output.
+func (this *FrameSink) cleanup() {
+	this.m.Lock()
+	defer this.m.Unlock()
+
+	this.free()
+
+	if this.closed {
+		cbUnregister(this.id)
+	}
+}
+
+// setTime records a player time update.
+func (this *FrameSink) setTime(t time.Duration) {
+	this.m.Lock()
+	this.time, this.timeAt = t, time.Now()
+	this.m.Unlock()
+}
+
+// planes returns the current plane addresses.
+func (this *FrameSink) planes() [3]unsafe.Pointer {
+	this.m.Lock()
+	defer this.m.Unlock()
+	return this.geom.planes
+}
+
+// display copies the current picture into an image and delivers it.
+func (this *FrameSink) display() {
+	this.m.Lock()
+	defer this.m.Unlock()
+
+	g := &this.geom
+	if this.closed || g.planes[0] == nil {
+		return
+	}
+
+	img := this.get(g)
+	copyFrame(img, g)
+
+	f := &Frame{Image: img, sink: this}
+	if !this.timeAt.IsZero() {
+		f.Time = this.time + time.Since(this.timeAt)
+	}
+
+	select {
+	case this.c <- f:
+	default:
+		this.put(img)
+	}
+}
+
+// get returns a pooled image matching g, or allocates a new one.
+func (this *FrameSink) get(g *frameGeometry) image.Image {
+	r := image.Rect(0, 0, g.width, g.height)
+
+	for {
+		var img image.Image
+
+		select {
+		case img = <-this.pool:
+		default:
+			if g.chroma == "I420" {
+				return image.NewYCbCr(r, image.YCbCrSubsampleRatio420)
+			}
+			return image.NewRGBA(r)
+		}
+
+		if img.Bounds() != r {
+			continue
+		}
+
+		if _, ok := img.(*image.YCbCr); ok == (g.chroma == "I420") {
+			return img
+		}
+	}
+}
+
+// put returns img to the pool, or discards it if the pool is full.
+func (this *FrameSink) put(img image.Image) {
+	select {
+	case this.pool <- img:
+	default:
+	}
+}
+
+// copyFrame converts the planes described by g into img.
+func copyFrame(img image.Image, g *frameGeometry) {
+	switch dst := img.(type) {
+	case *image.RGBA:
+		src := unsafe.Slice((*byte)(g.planes[0]), g.pitches[0]*g.lines[0])
+		bpp := 4
+		if g.chroma == "RV24" {
+			bpp = 3
+		}
+
+		for y := 0; y < g.height; y++ {
+			s := src[y*g.pitches[0]:]
+			d := dst.Pix[y*dst.Stride:]
+
+			// RV32 and RV24 are stored as BGR(X) in memory.
+			for x := 0; x < g.width; x++ {
+				d[x*4+0] = s[x*bpp+2]
+				d[x*4+1] = s[x*bpp+1]
+				d[x*4+2] = s[x*bpp+0]
+				d[x*4+3] = 0xff
+			}
+		}
+
+	case *image.YCbCr:
+		copyPlane(dst.Y, dst.YStride, g, 0, g.width)
+		copyPlane(dst.Cb, dst.CStride, g, 1, (g.width+1)/2)
+		copyPlane(dst.Cr, dst.CStride, g, 2, (g.width+1)/2)
+	}
+}
+
+// copyPlane copies width bytes from each line of plane n into dst.
+func copyPlane(dst []byte, stride int, g *frameGeometry, n, width int) {
+	src := unsafe.Slice((*byte)(g.planes[n]), g.pitches[n]*g.lines[n])
+
+	for y := 0; y < g.lines[n]; y++ {
+		copy(dst[y*stride:y*stride+width], src[y*g.pitches[n]:])
+	}
+}
+
+// alignUp rounds n up to a multiple of planeAlign.
+func alignUp(n int) int {
+	return (n + planeAlign - 1) &^ (planeAlign - 1)
+}
@@ -2,5 +2,7 @@
 // license. Its contents can be found at:
 // http://creativecommons.org/publicdomain/zero/1.0
 
+#include <stdint.h>
+#include <stdio.h>
 #include <stdlib.h>
 #include <vlc/vlc.h>
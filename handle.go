@@ -0,0 +1,206 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Handles returned by this package, such as those from Instance.OpenMediaFile()
+// or MediaList.At(), own one libvlc reference. Retain() adds one, Release()
+// gives one up, and once the last one is gone the handle is cleared so it
+// can not be used or released again. Close() gives up all of them at once
+// and may be called any number of times.
+//
+// Handles found in event payloads own no reference. Releasing them has the
+// same effect on libvlc as before, but does not clear them, and closing them
+// only clears them.
+
+// Reference bookkeeping of a handle.
+type ref struct {
+	n    int32  // Number of libvlc references owned by the handle.
+	fin  int32  // Non-zero if a finalizer is set.
+	leak uint64 // Leak tracker id, or 0.
+}
+
+var finalizers int32
+
+// SetFinalizers enables or disables runtime finalizers for handles created
+// from now on. Handles with a finalizer are closed by the garbage collector
+// once they become unreachable.
+//
+// libvlc keeps its own references where it needs them; a Player retains its
+// Media, for instance. But a Player or Instance which is only referenced by
+// libvlc callbacks is not kept alive by them, and is stopped and destroyed
+// when it is collected. Keep a reference to those, or close them explicitly.
+func SetFinalizers(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&finalizers, 1)
+	} else {
+		atomic.StoreInt32(&finalizers, 0)
+	}
+}
+
+// own marks h as owning one reference, and sets up its finalizer and leak
+// tracking.
+func (this *ref) own(h io.Closer, kind string) {
+	this.n = 1
+
+	if atomic.LoadInt32(&finalizers) != 0 {
+		this.fin = 1
+		runtime.SetFinalizer(h, func(c io.Closer) { c.Close() })
+	}
+
+	this.leak = leaks.add(kind)
+}
+
+func (this *ref) retain() {
+	atomic.AddInt32(&this.n, 1)
+}
+
+// release gives up one reference of the handle h. It returns true if that
+// was the last one, and the handle should be cleared.
+func (this *ref) release(h interface{}) bool {
+	for {
+		n := atomic.LoadInt32(&this.n)
+		if n == 0 {
+			return false
+		}
+
+		if atomic.CompareAndSwapInt32(&this.n, n, n-1) {
+			if n == 1 {
+				this.untrack(h)
+				return true
+			}
+			return false
+		}
+	}
+}
+
+// close gives up all references of the handle h, calling release once for
+// each of them.
+func (this *ref) close(h interface{}, release func()) {
+	for n := atomic.SwapInt32(&this.n, 0); n > 0; n-- {
+		release()
+	}
+	this.untrack(h)
+}
+
+func (this *ref) untrack(h interface{}) {
+	if atomic.SwapInt32(&this.fin, 0) != 0 {
+		runtime.SetFinalizer(h, nil)
+	}
+
+	if id := atomic.SwapUint64(&this.leak, 0); id != 0 {
+		leaks.remove(id)
+	}
+}
+
+// A handle which has not been released, as reported by Leaks().
+type Leak struct {
+	Type    string    // Handle type, e.g. "Media".
+	Created time.Time // Creation time.
+	Stack   string    // Stack trace of the call which created the handle.
+}
+
+// String formats the leak like a panic's stack trace.
+func (this *Leak) String() string {
+	return fmt.Sprintf("%s created at %s:\n%s", this.Type,
+		this.Created.Format(time.RFC3339Nano), this.Stack)
+}
+
+var leaks = &leakTracker{m: make(map[uint64]*Leak)}
+
+// TrackLeaks enables or disables leak tracking. While enabled, every handle
+// created is recorded with the stack trace of its creation until it is
+// released or closed. Disabling it forgets all recorded handles.
+//
+// Capturing stack traces is slow, so this is meant for debugging and tests.
+func TrackLeaks(enabled bool) {
+	leaks.Lock()
+	defer leaks.Unlock()
+
+	leaks.enabled = enabled
+	if !enabled {
+		leaks.m = make(map[uint64]*Leak)
+	}
+}
+
+// Leaks returns the handles created while leak tracking was enabled which
+// have not been released or closed yet, oldest first.
+func Leaks() []*Leak {
+	leaks.Lock()
+	defer leaks.Unlock()
+
+	ids := make([]uint64, 0, len(leaks.m))
+	for id := range leaks.m {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	list := make([]*Leak, len(ids))
+	for i, id := range ids {
+		list[i] = leaks.m[id]
+	}
+
+	return list
+}
+
+type leakTracker struct {
+	sync.Mutex
+	enabled bool
+	next    uint64
+	m       map[uint64]*Leak
+}
+
+// add records a new handle if tracking is enabled, and returns its id or 0.
+func (this *leakTracker) add(kind string) uint64 {
+	this.Lock()
+	enabled := this.enabled
+	this.Unlock()
+
+	if !enabled {
+		return 0
+	}
+
+	// Skip runtime.Callers, add and ref.own.
+	pc := make([]uintptr, 32)
+	pc = pc[:runtime.Callers(3, pc)]
+
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pc)
+
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&sb, "%s()\n\t%s:%d\n", f.Function, f.File, f.Line)
+
+		if !more {
+			break
+		}
+	}
+
+	l := &Leak{Type: kind, Created: time.Now(), Stack: sb.String()}
+
+	this.Lock()
+	defer this.Unlock()
+
+	this.next++
+	this.m[this.next] = l
+	return this.next
+}
+
+func (this *leakTracker) remove(id uint64) {
+	this.Lock()
+	delete(this.m, id)
+	this.Unlock()
+}
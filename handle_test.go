@@ -0,0 +1,112 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"strings"
+	"testing"
+)
+
+type fakeHandle struct {
+	ref
+}
+
+func (this *fakeHandle) Close() error { return nil }
+
+func newFakeHandle(kind string) *fakeHandle {
+	h := new(fakeHandle)
+	h.own(h, kind)
+	return h
+}
+
+func TestRefRelease(t *testing.T) {
+	h := newFakeHandle("fake")
+	h.retain()
+	h.retain()
+
+	for i, want := range []bool{false, false, true, false} {
+		if have := h.release(h); have != want {
+			t.Fatalf("release %d: have %v, want %v", i, have, want)
+		}
+	}
+
+	if h.n != 0 {
+		t.Fatalf("references: have %d, want 0", h.n)
+	}
+}
+
+func TestRefClose(t *testing.T) {
+	h := newFakeHandle("fake")
+	h.retain()
+	h.retain()
+
+	var n int
+	h.close(h, func() { n++ })
+	if n != 3 {
+		t.Fatalf("releases: have %d, want 3", n)
+	}
+
+	h.close(h, func() { n++ })
+	if n != 3 {
+		t.Fatalf("releases after second close: have %d, want 3", n)
+	}
+
+	if h.release(h) {
+		t.Fatalf("release after close: have true, want false")
+	}
+}
+
+func TestLeaks(t *testing.T) {
+	TrackLeaks(true)
+	defer TrackLeaks(false)
+
+	a := newFakeHandle("a")
+	b := newFakeHandle("b")
+	c := newFakeHandle("c")
+
+	if have := leakTypes(); have != "a b c" {
+		t.Fatalf("leaks: have %q, want %q", have, "a b c")
+	}
+
+	b.release(b)
+	c.retain()
+	c.release(c)
+	a.close(a, func() {})
+
+	if have := leakTypes(); have != "c" {
+		t.Fatalf("leaks after release: have %q, want %q", have, "c")
+	}
+
+	// The stack starts at the function which created the handle.
+	l := Leaks()[0]
+	if !strings.HasPrefix(l.Stack, "github.com/jteeuwen/go-vlc.newFakeHandle()\n") {
+		t.Fatalf("stack: have %q, want it to start at newFakeHandle", l.Stack)
+	}
+
+	if !strings.Contains(l.Stack, "TestLeaks()") {
+		t.Fatalf("stack: have %q, want it to contain TestLeaks", l.Stack)
+	}
+
+	if !strings.HasPrefix(l.String(), "c created at ") {
+		t.Fatalf("string: have %q", l.String())
+	}
+
+	TrackLeaks(false)
+	if have := len(Leaks()); have != 0 {
+		t.Fatalf("leaks after disabling: have %d, want 0", have)
+	}
+
+	if h := newFakeHandle("d"); h.leak != 0 {
+		t.Fatalf("leak id while disabled: have %d, want 0", h.leak)
+	}
+}
+
+func leakTypes() string {
+	var list []string
+	for _, l := range Leaks() {
+		list = append(list, l.Type)
+	}
+	return strings.Join(list, " ")
+}
@@ -5,14 +5,101 @@
 package vlc
 
 // #include "glue.h"
+//
+// extern void goLogCB(void*, int, char*, char*, unsigned, char*);
+//
+// static void goLogFormat(void* data, int level, const libvlc_log_t* ctx, const char* fmt, va_list args) {
+//    const char *module = NULL, *file = NULL;
+//    unsigned line = 0;
+//    va_list ap;
+//    char* msg;
+//    int n;
+//
+//    va_copy(ap, args);
+//    n = vsnprintf(NULL, 0, fmt, ap);
+//    va_end(ap);
+//
+//    if (n < 0 || (msg = malloc(n + 1)) == NULL) {
+//        return;
+//    }
+//
+//    vsnprintf(msg, n + 1, fmt, args);
+//    libvlc_log_get_context(ctx, &module, &file, &line);
+//    goLogCB(data, level, (char*)module, (char*)file, line, msg);
+//    free(msg);
+// }
+// static void goSetLog(libvlc_instance_t* p, uintptr_t userdata) {
+//    libvlc_log_set(p, goLogFormat, (void*)userdata);
+// }
+//
+// extern int     goMediaOpenCB(void*, void**, uint64_t*);
+// extern ssize_t goMediaReadCB(void*, unsigned char*, size_t);
+// extern int     goMediaSeekCB(void*, uint64_t);
+// extern void    goMediaCloseCB(void*);
+// extern void    goMediaFreedCB(const struct libvlc_event_t*, void*);
+//
+// static libvlc_media_t* goMediaNewCallbacks(libvlc_instance_t* p, int seekable, uintptr_t userdata) {
+//    libvlc_media_t* m = libvlc_media_new_callbacks(p, goMediaOpenCB, goMediaReadCB,
+//                            seekable ? goMediaSeekCB : NULL, goMediaCloseCB, (void*)userdata);
+//    if (m != NULL) {
+//        libvlc_event_attach(libvlc_media_event_manager(m), libvlc_MediaFreed, goMediaFreedCB, (void*)userdata);
+//    }
+//    return m;
+// }
+//
+// extern void goDialogErrorCB(uintptr_t, char*, char*);
+// extern void goDialogLoginCB(uintptr_t, libvlc_dialog_id*, char*, char*, char*, int);
+// extern void goDialogQuestionCB(uintptr_t, libvlc_dialog_id*, char*, char*, int, char*, char*, char*);
+// extern void goDialogProgressCB(uintptr_t, libvlc_dialog_id*, char*, char*, int, float, char*);
+// extern void goDialogCancelCB(uintptr_t, uintptr_t);
+// extern void goDialogUpdateCB(uintptr_t, uintptr_t, float, char*);
+//
+// static void goDialogError(void* data, const char* title, const char* text) {
+//    goDialogErrorCB((uintptr_t)data, (char*)title, (char*)text);
+// }
+// static void goDialogLogin(void* data, libvlc_dialog_id* id, const char* title, const char* text,
+//                           const char* username, bool store) {
+//    goDialogLoginCB((uintptr_t)data, id, (char*)title, (char*)text, (char*)username, store ? 1 : 0);
+// }
+// static void goDialogQuestion(void* data, libvlc_dialog_id* id, const char* title, const char* text,
+//                              libvlc_dialog_question_type type, const char* cancel,
+//                              const char* action1, const char* action2) {
+//    goDialogQuestionCB((uintptr_t)data, id, (char*)title, (char*)text, type,
+//                       (char*)cancel, (char*)action1, (char*)action2);
+// }
+// static void goDialogProgress(void* data, libvlc_dialog_id* id, const char* title, const char* text,
+//                              bool indeterminate, float position, const char* cancel) {
+//    goDialogProgressCB((uintptr_t)data, id, (char*)title, (char*)text, indeterminate ? 1 : 0,
+//                       position, (char*)cancel);
+// }
+// static void goDialogCancel(void* data, libvlc_dialog_id* id) {
+//    goDialogCancelCB((uintptr_t)data, (uintptr_t)libvlc_dialog_get_context(id));
+// }
+// static void goDialogUpdate(void* data, libvlc_dialog_id* id, float position, const char* text) {
+//    goDialogUpdateCB((uintptr_t)data, (uintptr_t)libvlc_dialog_get_context(id), position, (char*)text);
+// }
+//
+// static const libvlc_dialog_cbs goDialogCallbacks = {
+//    goDialogError, goDialogLogin, goDialogQuestion, goDialogProgress, goDialogCancel, goDialogUpdate,
+// };
+//
+// static void goSetDialog(libvlc_instance_t* p, uintptr_t userdata) {
+//    libvlc_dialog_set_callbacks(p, userdata ? &goDialogCallbacks : NULL, (void*)userdata);
+// }
 import "C"
 import (
+	"io"
+	"sync/atomic"
 	"unsafe"
 )
 
 // A single libvlc instance.
 type Instance struct {
-	ptr *C.libvlc_instance_t
+	ptr    *C.libvlc_instance_t
+	ref    ref
+	log    *logSink
+	dialog uintptr
+	vlm    *vlmState
 }
 
 // New creates and initializes a new VLC instance with the given parameters.
@@ -25,7 +112,8 @@ func New(argv []string) (i *Instance, err error) {
 	}
 
 	if c := C.libvlc_new(C.int(len(argv)), *(***C.char)(unsafe.Pointer(&cstr))); c != nil {
-		i = &Instance{c}
+		i = &Instance{ptr: c, log: newLogSink(), vlm: newVlmState()}
+		i.ref.own(i, "Instance")
 	} else {
 		err = checkError()
 	}
@@ -44,20 +132,50 @@ func (this *Instance) Retain() (err error) {
 	}
 
 	C.libvlc_retain(this.ptr)
+	this.ref.retain()
 	return
 }
 
 // Release decreases the reference count of the instance and destroys it
-// when it reaches zero.
+// when it reaches zero. Giving up the last reference of this handle also
+// removes the log and dialog handlers set through it.
 func (this *Instance) Release() (err error) {
 	if this.ptr == nil {
 		return &VLCError{"Instance is nil"}
 	}
 
+	last := this.ref.release(this)
+	if last {
+		this.unregister()
+	}
+
 	C.libvlc_release(this.ptr)
+	if last {
+		this.ptr = nil
+	}
 	return
 }
 
+// Close gives up every reference this handle holds on the instance, and
+// removes the log and dialog handlers set through it. Objects created from
+// the instance hold references of their own, so libvlc only destroys it
+// once those are released too. Calling Close again does nothing.
+func (this *Instance) Close() error {
+	if this.ptr != nil {
+		this.unregister()
+		this.ref.close(this, func() { C.libvlc_release(this.ptr) })
+		this.ptr = nil
+	}
+	return nil
+}
+
+// unregister removes the log and dialog handlers, so libvlc no longer calls
+// into them and their callback ids are freed.
+func (this *Instance) unregister() {
+	this.SetLogger(nil, nil)
+	this.SetDialogHandler(nil)
+}
+
 // StartUI tries to start a user interface for the Instance.
 // Specify an empty name to use the default.
 func (this *Instance) StartUI(name string) (err error) {
@@ -104,37 +222,87 @@ func (this *Instance) Wait() error {
 	return nil
 }
 
-// TODO(henrym): Log API has changed. Left here as a reminder to update to new libvlc log API
+// SetLogger routes libvlc's log messages to the given handler. Specify a nil
+// handler to stop logging. Messages emitted while the instance was being
+// created can not be captured this way.
 //
-// LogVerbosity returns the VLC messaging verbosity level.
-//func (this *Instance) LogVerbosity() uint {
-//	if this.ptr == nil {
-//		return 0
-//	}
-//	return uint(C.libvlc_get_log_verbosity(this.ptr))
-//}
-
-// SetLogVerbosity sets the VLC messaging verbosity level.
-//func (this *Instance) SetLogVerbosity(v uint) {
-//	if this.ptr != nil {
-//		C.libvlc_set_log_verbosity(this.ptr, C.uint(v))
-//	}
-//}
-
-// OpenLog opens a VLC message log instance.
-//func (this *Instance) OpenLog() (*Log, error) {
-//	if this.ptr == nil {
-//		return nil, os.EINVAL
-//	}
+// The handler is invoked from libvlc threads. It is removed when the instance
+// is closed or released for the last time.
+func (this *Instance) SetLogger(h LogHandler, userdata interface{}) error {
+	if this.ptr == nil {
+		return &VLCError{"Instance is nil"}
+	}
+
+	this.log.m.Lock()
+	defer this.log.m.Unlock()
+
+	if this.log.id != 0 {
+		C.libvlc_log_unset(this.ptr)
+		cbUnregister(this.log.id)
+		this.log.id = 0
+	}
+
+	if h != nil {
+		this.log.id = cbRegister(&logReq{h, userdata, this.log})
+		C.goSetLog(this.ptr, C.uintptr_t(this.log.id))
+	}
+
+	return nil
+}
+
+// SetDialogHandler sets the handler which answers dialogs raised by libvlc,
+// such as login prompts for protected streams or questions about untrusted
+// certificates. Specify a nil handler to stop handling dialogs; libvlc then
+// fails whatever operation needed an answer.
 //
-//	if c := C.libvlc_log_open(this.ptr); c != nil {
-//		l := new(Log)
-//		l.fromC(c)
-//		return l, nil
-//	}
+// The handler is removed when the instance is closed or released for the
+// last time.
+func (this *Instance) SetDialogHandler(h DialogHandler) error {
+	if this.ptr == nil {
+		return &VLCError{"Instance is nil"}
+	}
+
+	if this.dialog != 0 {
+		C.goSetDialog(this.ptr, 0)
+		cbUnregister(this.dialog)
+		this.dialog = 0
+	}
+
+	if h != nil {
+		this.dialog = cbRegister(&dialogReq{h})
+		C.goSetDialog(this.ptr, C.uintptr_t(this.dialog))
+	}
+
+	return nil
+}
+
+// LogVerbosity returns the verbosity level used to filter messages passed to
+// the handler set with Instance.SetLogger().
+func (this *Instance) LogVerbosity() uint {
+	if this.ptr == nil {
+		return 0
+	}
+	return uint(atomic.LoadUint32(&this.log.verbosity))
+}
+
+// SetLogVerbosity sets the verbosity level used to filter messages passed to
+// the handler set with Instance.SetLogger().
 //
-//	return nil, checkError()
-//}
+//	0: Errors only.
+//	1: Errors and warnings.
+//	2: Errors, warnings and informational messages.
+//	3: Everything, including debug messages. This is the default.
+func (this *Instance) SetLogVerbosity(v uint) {
+	if this.ptr == nil {
+		return
+	}
+
+	if v > 3 {
+		v = 3
+	}
+
+	atomic.StoreUint32(&this.log.verbosity, uint32(v))
+}
 
 // OpenMediaUri loads a media instance from the given uri.
 func (this *Instance) OpenMediaUri(uri string) (*Media, error) {
@@ -146,7 +314,7 @@ func (this *Instance) OpenMediaUri(uri string) (*Media, error) {
 	defer C.free(unsafe.Pointer(c))
 
 	if m := C.libvlc_media_new_location(this.ptr, c); m != nil {
-		return &Media{m}, nil
+		return newMedia(m), nil
 	}
 
 	return nil, checkError()
@@ -162,7 +330,7 @@ func (this *Instance) OpenMediaFile(path string) (*Media, error) {
 	defer C.free(unsafe.Pointer(c))
 
 	if m := C.libvlc_media_new_path(this.ptr, c); m != nil {
-		return &Media{m}, nil
+		return newMedia(m), nil
 	}
 
 	return nil, checkError()
@@ -188,12 +356,50 @@ func (this *Instance) OpenMediaFd(fd int) (*Media, error) {
 	}
 
 	if m := C.libvlc_media_new_fd(this.ptr, C.int(fd)); m != nil {
-		return &Media{m}, nil
+		return newMedia(m), nil
 	}
 
 	return nil, checkError()
 }
 
+// OpenMediaReader creates a media instance which reads its data from r.
+//
+// The resulting media is not seekable and can only be played once, unless
+// playback stopped before anything was read. Use Instance.OpenMediaReadSeeker()
+// if r supports seeking. The reader is not closed by this library.
+func (this *Instance) OpenMediaReader(r io.Reader) (*Media, error) {
+	return this.openMediaCallbacks(&readerReq{r: r})
+}
+
+// OpenMediaReadSeeker creates a media instance which reads its data from r.
+//
+// The media is seekable and can be played any number of times; r is rewound to
+// the start whenever libvlc opens it. The reader is not closed by this library.
+func (this *Instance) OpenMediaReadSeeker(r io.ReadSeeker) (*Media, error) {
+	return this.openMediaCallbacks(&readerReq{r: r, s: r})
+}
+
+func (this *Instance) openMediaCallbacks(req *readerReq) (*Media, error) {
+	if this.ptr == nil {
+		return nil, &VLCError{"Instance is nil"}
+	}
+
+	var seekable C.int
+	if req.s != nil {
+		seekable = 1
+	}
+
+	id := cbRegister(req)
+
+	if m := C.goMediaNewCallbacks(this.ptr, seekable, C.uintptr_t(id)); m != nil {
+		trackReader(m, id)
+		return newMedia(m), nil
+	}
+
+	cbUnregister(id)
+	return nil, checkError()
+}
+
 // OpenMediaNode creates a media instance as an empty node with a given name.
 func (this *Instance) OpenMediaNode(name string) (*Media, error) {
 	if this.ptr == nil {
@@ -204,7 +410,7 @@ func (this *Instance) OpenMediaNode(name string) (*Media, error) {
 	defer C.free(unsafe.Pointer(c))
 
 	if m := C.libvlc_media_new_as_node(this.ptr, c); m != nil {
-		return &Media{m}, nil
+		return newMedia(m), nil
 	}
 
 	return nil, checkError()
@@ -217,7 +423,7 @@ func (this *Instance) NewPlayer() (*Player, error) {
 	}
 
 	if c := C.libvlc_media_player_new(this.ptr); c != nil {
-		return &Player{c}, nil
+		return newPlayer(c), nil
 	}
 
 	return nil, checkError()
@@ -230,7 +436,7 @@ func (this *Instance) NewList() (*MediaList, error) {
 	}
 
 	if c := C.libvlc_media_list_new(this.ptr); c != nil {
-		return &MediaList{c}, nil
+		return newMediaList(c), nil
 	}
 
 	return nil, checkError()
@@ -243,7 +449,7 @@ func (this *Instance) NewListPlayer() (*ListPlayer, error) {
 	}
 
 	if c := C.libvlc_media_list_player_new(this.ptr); c != nil {
-		return &ListPlayer{c}, nil
+		return newListPlayer(c), nil
 	}
 
 	return nil, checkError()
@@ -256,7 +462,7 @@ func (this *Instance) NewLibrary() (*Library, error) {
 	}
 
 	if c := C.libvlc_media_library_new(this.ptr); c != nil {
-		return &Library{c}, nil
+		return newLibrary(c), nil
 	}
 
 	return nil, checkError()
@@ -272,7 +478,7 @@ func (this *Instance) Discoverer(name string) (*Discoverer, error) {
 	defer C.free(unsafe.Pointer(s))
 
 	if c := C.libvlc_media_discoverer_new_from_name(this.ptr, s); c != nil {
-		return &Discoverer{c}, nil
+		return newDiscoverer(c), nil
 	}
 
 	return nil, checkError()
@@ -285,18 +491,18 @@ func (this *Instance) VlmRelease() error {
 	}
 
 	C.libvlc_vlm_release(this.ptr)
+	this.vlm.clear()
 	return checkError()
 }
 
 // VlmAddBroadcast adds a broadcast with given input.
 //
-//     name: The name of the new broadcast.
-//     input: The input MRL.
-//     output: The output MRL (the parameter to the "sout" variable).
-//     options: Additional options.
-//     enabled: Enable the new broadcast?
-//     loop: Should this broadcast be played in loop?
-//
+//	name: The name of the new broadcast.
+//	input: The input MRL.
+//	output: The output MRL (the parameter to the "sout" variable).
+//	options: Additional options.
+//	enabled: Enable the new broadcast?
+//	loop: Should this broadcast be played in loop?
 func (this *Instance) VlmAddBroadcast(name, input, output string, options []string, enabled, loop bool) error {
 	if this.ptr == nil {
 		return &VLCError{"Instance is nil"}
@@ -321,7 +527,9 @@ func (this *Instance) VlmAddBroadcast(name, input, output string, options []stri
 		g = 1
 	}
 
-	C.libvlc_vlm_add_broadcast(this.ptr, a, b, c, d, *(***C.char)(unsafe.Pointer(&e)), f, g)
+	if C.libvlc_vlm_add_broadcast(this.ptr, a, b, c, d, *(***C.char)(unsafe.Pointer(&e)), f, g) == 0 {
+		this.vlm.set(name, options)
+	}
 
 	C.free(unsafe.Pointer(a))
 	C.free(unsafe.Pointer(b))
@@ -336,12 +544,11 @@ func (this *Instance) VlmAddBroadcast(name, input, output string, options []stri
 
 // VlmAddVOD adds a VOD with given input.
 //
-//     name: The name of the new broadcast.
-//     input: The input MRL.
-//     options: Additional options.
-//     mux: The muxer of the vod media.
-//     enabled: Enable the new broadcast?
-//
+//	name: The name of the new broadcast.
+//	input: The input MRL.
+//	options: Additional options.
+//	mux: The muxer of the vod media.
+//	enabled: Enable the new broadcast?
 func (this *Instance) VlmAddVOD(name, input, output, mux string, options []string, enabled bool) error {
 	if this.ptr == nil {
 		return &VLCError{"Instance is nil"}
@@ -363,7 +570,9 @@ func (this *Instance) VlmAddVOD(name, input, output, mux string, options []strin
 
 	f := C.CString(mux)
 
-	C.libvlc_vlm_add_vod(this.ptr, a, b, c, *(***C.char)(unsafe.Pointer(&d)), e, f)
+	if C.libvlc_vlm_add_vod(this.ptr, a, b, c, *(***C.char)(unsafe.Pointer(&d)), e, f) == 0 {
+		this.vlm.set(name, options)
+	}
 
 	C.free(unsafe.Pointer(a))
 	C.free(unsafe.Pointer(b))
@@ -383,7 +592,9 @@ func (this *Instance) VlmDelete(name string) error {
 	}
 
 	c := C.CString(name)
-	C.libvlc_vlm_del_media(this.ptr, c)
+	if C.libvlc_vlm_del_media(this.ptr, c) == 0 {
+		this.vlm.remove(name)
+	}
 	C.free(unsafe.Pointer(c))
 
 	return checkError()
@@ -483,13 +694,12 @@ func (this *Instance) VlmSetMux(name, mux string) error {
 // VlmChangeMedia edits the parameters of a media. This will delete all existing
 // inputs and add the specified one.
 //
-//     name: The name of the new broadcast.
-//     input: The input MRL.
-//     output: The output MRL (the parameter to the "sout" variable).
-//     options: Additional options.
-//     enabled: Enable the new broadcast?
-//     loop: Should this broadcast be played in loop?
-//
+//	name: The name of the new broadcast.
+//	input: The input MRL.
+//	output: The output MRL (the parameter to the "sout" variable).
+//	options: Additional options.
+//	enabled: Enable the new broadcast?
+//	loop: Should this broadcast be played in loop?
 func (this *Instance) VlmChangeMedia(name, input, output string, options []string, enabled, loop bool) error {
 	if this.ptr == nil {
 		return &VLCError{"Instance is nil"}
@@ -514,7 +724,9 @@ func (this *Instance) VlmChangeMedia(name, input, output string, options []strin
 		g = 1
 	}
 
-	C.libvlc_vlm_change_media(this.ptr, a, b, c, d, *(***C.char)(unsafe.Pointer(&e)), f, g)
+	if C.libvlc_vlm_change_media(this.ptr, a, b, c, d, *(***C.char)(unsafe.Pointer(&e)), f, g) == 0 {
+		this.vlm.set(name, options)
+	}
 
 	C.free(unsafe.Pointer(a))
 	C.free(unsafe.Pointer(b))
@@ -577,7 +789,8 @@ func (this *Instance) VlmSeek(name string, percentage float32) error {
 
 // VlmMediaInfo returns information about the named media as a JSON string.
 //
-// Note: This function is mainly intended for debugging use,
+// Note: This function is mainly intended for debugging use. Use
+// Instance.VlmMedia() or Instance.VlmList() to get structured information.
 func (this *Instance) VlmMediaInfo(name string) (s string, err error) {
 	if this.ptr == nil {
 		return "", &VLCError{"Instance is nil"}
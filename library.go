@@ -10,6 +10,14 @@ import "C"
 // A media library
 type Library struct {
 	ptr *C.libvlc_media_library_t
+	ref ref
+}
+
+// newLibrary wraps a library reference owned by the caller.
+func newLibrary(c *C.libvlc_media_library_t) *Library {
+	l := &Library{ptr: c}
+	l.ref.own(l, "Library")
+	return l
 }
 
 // Retain increments the reference count of the instance.
@@ -18,6 +26,7 @@ func (this *Library) Retain() (err error) {
 		return &VLCError{"Library is nil"}
 	}
 	C.libvlc_media_library_retain(this.ptr)
+	this.ref.retain()
 	return
 }
 
@@ -28,9 +37,23 @@ func (this *Library) Release() (err error) {
 	}
 
 	C.libvlc_media_library_release(this.ptr)
+	if this.ref.release(this) {
+		this.ptr = nil
+	}
 	return
 }
 
+// Close gives up every reference this handle holds on the library. Media
+// lists obtained from it stay valid, as they hold their own. Calling Close
+// again does nothing.
+func (this *Library) Close() error {
+	if this.ptr != nil {
+		this.ref.close(this, func() { C.libvlc_media_library_release(this.ptr) })
+		this.ptr = nil
+	}
+	return nil
+}
+
 // Load loads the library contents.
 func (this *Library) Load() error {
 	if this.ptr == nil {
@@ -47,7 +70,7 @@ func (this *Library) Items() (*MediaList, error) {
 	}
 
 	if c := C.libvlc_media_library_media_list(this.ptr); c != nil {
-		return &MediaList{c}, nil
+		return newMediaList(c), nil
 	}
 
 	return nil, checkError()
@@ -11,6 +11,14 @@ import "C"
 // This is basically a wrapper for vlc.Player that takes care of playlist rotation.
 type ListPlayer struct {
 	ptr *C.libvlc_media_list_player_t
+	ref ref
+}
+
+// newListPlayer wraps a player reference owned by the caller.
+func newListPlayer(c *C.libvlc_media_list_player_t) *ListPlayer {
+	p := &ListPlayer{ptr: c}
+	p.ref.own(p, "ListPlayer")
+	return p
 }
 
 // Release decreases the reference count of the instance and destroys it when it reaches zero.
@@ -20,9 +28,24 @@ func (this *ListPlayer) Release() (err error) {
 	}
 
 	C.libvlc_media_list_player_release(this.ptr)
+	if this.ref.release(this) {
+		this.ptr = nil
+	}
 	return
 }
 
+// Close gives up every reference this handle holds on the list player. Once
+// libvlc destroys it, playback stops and the list and player set on it are
+// released; their own handles remain valid. Calling Close again does
+// nothing.
+func (this *ListPlayer) Close() error {
+	if this.ptr != nil {
+		this.ref.close(this, func() { C.libvlc_media_list_player_release(this.ptr) })
+		this.ptr = nil
+	}
+	return nil
+}
+
 // Events returns an Eventmanager for this player.
 func (this *ListPlayer) Events() (*EventManager, error) {
 	if this.ptr == nil {
@@ -36,6 +59,19 @@ func (this *ListPlayer) Events() (*EventManager, error) {
 	return nil, checkError()
 }
 
+// Subscribe delivers the given MediaListPlayer* events of this list player on
+// a channel. Playback events, such as MediaPlayerTimeChanged, are emitted by
+// the underlying player instead; set one with Replace() to subscribe to them.
+// It is short for Events() followed by EventManager.Subscribe().
+func (this *ListPlayer) Subscribe(types ...EventType) (<-chan *Event, func(), error) {
+	em, err := this.Events()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return em.Subscribe(types...)
+}
+
 // Replace replaces the Player instance in this listplayer with a new one.
 func (this *ListPlayer) Replace(p *Player) error {
 	if this.ptr == nil || p.ptr == nil {
@@ -0,0 +1,133 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// A single log message emitted by libvlc.
+type LogMessage struct {
+	Priority LogPriority // Message severity.
+	Module   string      // Name of the VLC module emitting the message. May be empty.
+	File     string      // Source file emitting the message. May be empty.
+	Line     int         // Source line emitting the message, or 0 if unknown.
+	Message  string      // The formatted message text.
+}
+
+// Log callback handler. It is invoked from libvlc threads, possibly
+// concurrently, so it should not block and must not call Instance.SetLogger.
+type LogHandler func(msg *LogMessage, userdata interface{})
+
+func (this LogPriority) String() string {
+	switch this {
+	case Debug:
+		return "debug"
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("LogPriority(%d)", uint8(this))
+}
+
+// logPriority maps a libvlc log level (LIBVLC_DEBUG, LIBVLC_NOTICE,
+// LIBVLC_WARNING or LIBVLC_ERROR) onto its LogPriority.
+func logPriority(level int) LogPriority {
+	switch level {
+	case 4:
+		return Error
+	case 3:
+		return Warning
+	case 2:
+		return Info
+	}
+	return Debug
+}
+
+// verbosity returns the lowest verbosity level at which messages of this
+// priority are delivered.
+func (this LogPriority) verbosity() uint32 {
+	switch this {
+	case Error:
+		return 0
+	case Warning:
+		return 1
+	case Info:
+		return 2
+	}
+	return 3
+}
+
+// slogLevel maps the priority onto the equivalent log/slog level.
+func (this LogPriority) slogLevel() slog.Level {
+	switch this {
+	case Error:
+		return slog.LevelError
+	case Warning:
+		return slog.LevelWarn
+	case Info:
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug
+}
+
+// Per-instance log state. The handler itself is registered separately
+// (see logReq) so it can be swapped while libvlc is emitting messages.
+type logSink struct {
+	m         sync.Mutex
+	id        uintptr // Callback registry id of the active logReq, or 0.
+	verbosity uint32  // Accessed atomically.
+}
+
+func newLogSink() *logSink {
+	return &logSink{verbosity: 3}
+}
+
+func (this *logSink) allows(p LogPriority) bool {
+	return p.verbosity() <= atomic.LoadUint32(&this.verbosity)
+}
+
+// Used in Instance.SetLogger() to route messages to a Go handler.
+type logReq struct {
+	h    LogHandler
+	d    interface{}
+	sink *logSink
+}
+
+// SlogLogger returns a LogHandler which forwards libvlc messages to l,
+// with the module, file and line attached as attributes.
+func SlogLogger(l *slog.Logger) LogHandler {
+	return func(msg *LogMessage, userdata interface{}) {
+		l.LogAttrs(context.Background(), msg.Priority.slogLevel(), msg.Message,
+			slog.String("module", msg.Module),
+			slog.String("file", msg.File),
+			slog.Int("line", msg.Line),
+		)
+	}
+}
+
+// WriterLogger returns a LogHandler which writes libvlc messages to w,
+// one line per message. Writes are serialized.
+func WriterLogger(w io.Writer) LogHandler {
+	var m sync.Mutex
+
+	return func(msg *LogMessage, userdata interface{}) {
+		m.Lock()
+		if len(msg.Module) > 0 {
+			fmt.Fprintf(w, "[%s] %s: %s\n", msg.Priority, msg.Module, msg.Message)
+		} else {
+			fmt.Fprintf(w, "[%s] %s\n", msg.Priority, msg.Message)
+		}
+		m.Unlock()
+	}
+}
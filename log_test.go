@@ -0,0 +1,73 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLogPriority(t *testing.T) {
+	for _, tt := range []struct {
+		level int
+		p     LogPriority
+		s     string
+	}{
+		{0, Debug, "debug"},
+		{1, Debug, "debug"},
+		{2, Info, "info"},
+		{3, Warning, "warning"},
+		{4, Error, "error"},
+	} {
+		p := logPriority(tt.level)
+		if p != tt.p || p.String() != tt.s {
+			t.Errorf("level %d: have %v, want %v", tt.level, p, tt.s)
+		}
+	}
+
+	if have := LogPriority(9).String(); have != "LogPriority(9)" {
+		t.Errorf("unknown priority: have %q, want %q", have, "LogPriority(9)")
+	}
+}
+
+func TestLogVerbosity(t *testing.T) {
+	sink := newLogSink()
+
+	for _, tt := range []struct {
+		verbosity uint32
+		allowed   []LogPriority
+	}{
+		{0, []LogPriority{Error}},
+		{1, []LogPriority{Error, Warning}},
+		{2, []LogPriority{Error, Warning, Info}},
+		{3, []LogPriority{Error, Warning, Info, Debug}},
+	} {
+		sink.verbosity = tt.verbosity
+
+		for _, p := range []LogPriority{Error, Warning, Info, Debug} {
+			want := false
+			for _, a := range tt.allowed {
+				want = want || a == p
+			}
+
+			if have := sink.allows(p); have != want {
+				t.Errorf("verbosity %d, %v: have %v, want %v", tt.verbosity, p, have, want)
+			}
+		}
+	}
+}
+
+func TestWriterLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log := WriterLogger(&buf)
+
+	log(&LogMessage{Priority: Warning, Module: "avcodec", Message: "late picture"}, nil)
+	log(&LogMessage{Priority: Error, Message: "no suitable decoder"}, nil)
+
+	want := "[warning] avcodec: late picture\n[error] no suitable decoder\n"
+	if have := buf.String(); have != want {
+		t.Fatalf("output: have %q, want %q", have, want)
+	}
+}
@@ -5,13 +5,66 @@
 package vlc
 
 // #include "glue.h"
+//
+// extern void goMediaOptionsFreedCB(const struct libvlc_event_t*, void*);
+// extern void goMediaFreedCB(const struct libvlc_event_t*, void*);
+//
+// static void goTrackOptions(libvlc_media_t* m) {
+//    libvlc_event_attach(libvlc_media_event_manager(m), libvlc_MediaFreed, goMediaOptionsFreedCB, NULL);
+// }
+// static void goTrackReader(libvlc_media_t* m, uintptr_t userdata) {
+//    libvlc_event_attach(libvlc_media_event_manager(m), libvlc_MediaFreed, goMediaFreedCB, (void*)userdata);
+// }
 import "C"
 import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
 	"unsafe"
+
+	"github.com/jteeuwen/go-vlc/sout"
+	"github.com/jteeuwen/go-vlc/vlcapi"
 )
 
 type Media struct {
 	ptr *C.libvlc_media_t
+	ref ref
+}
+
+// newMedia wraps a media reference owned by the caller.
+func newMedia(c *C.libvlc_media_t) *Media {
+	m := &Media{ptr: c}
+	m.ref.own(m, "Media")
+	return m
+}
+
+// Options added to each media, as libvlc does not report them. Entries are
+// removed when the media is freed.
+var mediaOptions = struct {
+	sync.Mutex
+	m map[*C.libvlc_media_t][]string
+}{m: make(map[*C.libvlc_media_t][]string)}
+
+// addOption records an option added to the media.
+func (this *Media) addOption(option string) {
+	mediaOptions.Lock()
+	defer mediaOptions.Unlock()
+
+	list, ok := mediaOptions.m[this.ptr]
+	if !ok {
+		C.goTrackOptions(this.ptr)
+	}
+
+	mediaOptions.m[this.ptr] = append(list, option)
+}
+
+// Options returns the options added with Media.AddOption() or
+// Media.AddOptionFlag(), in order.
+func (this *Media) Options() []string {
+	mediaOptions.Lock()
+	defer mediaOptions.Unlock()
+	return append([]string(nil), mediaOptions.m[this.ptr]...)
 }
 
 // Retain increments the reference count of this Media instance.
@@ -21,6 +74,7 @@ func (this *Media) Retain() (err error) {
 	}
 
 	C.libvlc_media_retain(this.ptr)
+	this.ref.retain()
 	return
 }
 
@@ -33,9 +87,23 @@ func (this *Media) Release() (err error) {
 	}
 
 	C.libvlc_media_release(this.ptr)
+	if this.ref.release(this) {
+		this.ptr = nil
+	}
 	return
 }
 
+// Close gives up every reference this handle holds on the media. Players and
+// lists it was added to hold their own references, so it stays usable there.
+// Calling Close again does nothing.
+func (this *Media) Close() error {
+	if this.ptr != nil {
+		this.ref.close(this, func() { C.libvlc_media_release(this.ptr) })
+		this.ptr = nil
+	}
+	return nil
+}
+
 // Duplicate duplicates the media object.
 func (this *Media) Duplicate() (*Media, error) {
 	if this.ptr == nil {
@@ -43,7 +111,17 @@ func (this *Media) Duplicate() (*Media, error) {
 	}
 
 	if c := C.libvlc_media_duplicate(this.ptr); c != nil {
-		return &Media{c}, nil
+		// The duplicate reads through the same callbacks as the original.
+		if id := readerOf(this.ptr); id != 0 {
+			trackReader(c, id)
+			C.goTrackReader(c, C.uintptr_t(id))
+		}
+
+		m := newMedia(c)
+		for _, o := range this.Options() {
+			m.addOption(o)
+		}
+		return m, nil
 	}
 
 	return nil, checkError()
@@ -64,6 +142,7 @@ func (this *Media) AddOption(options string) error {
 	c := C.CString(options)
 	C.libvlc_media_add_option(this.ptr, c)
 	C.free(unsafe.Pointer(c))
+	this.addOption(options)
 
 	return checkError()
 }
@@ -83,10 +162,42 @@ func (this *Media) AddOptionFlag(options string, flags uint32) error {
 	c := C.CString(options)
 	C.libvlc_media_add_option_flag(this.ptr, c, C.uint(flags))
 	C.free(unsafe.Pointer(c))
+	this.addOption(options)
 
 	return checkError()
 }
 
+// SetSeekMode chooses whether a player seeks to the exact time within this
+// media, or to the nearest key frame. libVLC 3.0 reads it when playback
+// starts, so set it before Player.Play(). The default is SeekPrecise.
+func (this *Media) SetSeekMode(mode SeekMode) error {
+	switch mode {
+	case SeekPrecise:
+		return this.AddOption(":no-input-fast-seek")
+	case SeekFast:
+		return this.AddOption(":input-fast-seek")
+	}
+
+	return &VLCError{"Unknown seek mode: " + mode.String()}
+}
+
+// SeekMode returns the seek mode chosen with Media.SetSeekMode(), or with
+// the :input-fast-seek option.
+func (this *Media) SeekMode() SeekMode {
+	return vlcapi.MediaSeekMode(this.Options())
+}
+
+// SetStreamOutput makes the media be sent through the given stream output
+// chain when played, instead of being rendered locally. See package sout for
+// building chains. Like Media.AddOption(), this can not be undone.
+func (this *Media) SetStreamOutput(c sout.Chain) error {
+	if len(c) == 0 {
+		return &VLCError{"Stream output chain is empty"}
+	}
+
+	return this.AddOption(":sout=" + c.String())
+}
+
 // Mrl returns the media resource locator (mrl) from a media descriptor object.
 func (this *Media) Mrl() (s string) {
 	if this.ptr == nil {
@@ -162,8 +273,9 @@ func (this *Media) Stats() (*Stats, error) {
 
 	var c C.libvlc_media_stats_t
 
-	if C.libvlc_media_get_stats(this.ptr, &c) != 0 {
-		return nil, checkError()
+	// libvlc returns true on success here.
+	if C.libvlc_media_get_stats(this.ptr, &c) == 0 {
+		return nil, &VLCError{"Statistics are not available"}
 	}
 
 	return &Stats{&c}, nil
@@ -178,7 +290,7 @@ func (this *Media) SubItems() (*MediaList, error) {
 	}
 
 	if c := C.libvlc_media_subitems(this.ptr); c != nil {
-		return &MediaList{c}, nil
+		return newMediaList(c), nil
 	}
 
 	return nil, checkError()
@@ -198,6 +310,18 @@ func (this *Media) Events() (*EventManager, error) {
 	return nil, checkError()
 }
 
+// Subscribe delivers the given Media* events of this media, such as
+// MediaStateChanged or MediaParsedChanged, on a channel. It is short for
+// Events() followed by EventManager.Subscribe().
+func (this *Media) Subscribe(types ...EventType) (<-chan *Event, func(), error) {
+	em, err := this.Events()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return em.Subscribe(types...)
+}
+
 // Duration returns the duration in milliseconds for the current media instance.
 func (this *Media) Duration() int64 {
 	if this.ptr == nil {
@@ -206,6 +330,22 @@ func (this *Media) Duration() int64 {
 	return int64(C.libvlc_media_get_duration(this.ptr))
 }
 
+// Length returns the duration of the media. It returns an error if the
+// duration is not known, which is the case for media which have not been
+// parsed or played yet, and for live streams.
+func (this *Media) Length() (time.Duration, error) {
+	if this.ptr == nil {
+		return 0, &VLCError{"Media is nil"}
+	}
+
+	d := this.Duration()
+	if d < 0 {
+		return 0, &VLCError{"Media duration is unknown"}
+	}
+
+	return time.Duration(d) * time.Millisecond, nil
+}
+
 // Parse the current media source.
 //
 // This fetches (local) meta data and track information.
@@ -229,6 +369,83 @@ func (this *Media) ParseAsync() {
 	}
 }
 
+// Options for Media.ParseContext().
+type ParseOptions struct {
+	Flags   ParseFlag     // What to parse and fetch.
+	Timeout time.Duration // Maximum parse time. Zero selects libvlc's default.
+}
+
+// ParseContext parses the media source and waits until parsing has finished,
+// the timeout in opts expires or ctx is done, whichever happens first.
+//
+// It returns the resulting parse status: PSDone on success, PSFailed if the
+// media could not be parsed, PSTimeout if parsing took too long, or PSSkipped
+// if the media is a network source and opts.Flags does not include
+// ParseNetwork. If ctx is done first, parsing is stopped and ctx.Err() is
+// returned.
+//
+// If the media has already been parsed, its current status is returned
+// immediately.
+func (this *Media) ParseContext(ctx context.Context, opts ParseOptions) (ParsedStatus, error) {
+	if this.ptr == nil {
+		return PSNone, &VLCError{"Media is nil"}
+	}
+
+	// libvlc does not notify us about media which was parsed before.
+	if s := this.ParsedStatus(); s != PSNone {
+		return s, nil
+	}
+
+	em, err := this.Events()
+	if err != nil {
+		return PSNone, err
+	}
+
+	events, cancel, err := em.Subscribe(MediaParsedChanged)
+	if err != nil {
+		return PSNone, err
+	}
+	defer cancel()
+
+	timeout := -1
+	if opts.Timeout > 0 {
+		timeout = int(opts.Timeout / time.Millisecond)
+		if timeout == 0 {
+			timeout = 1
+		}
+	}
+
+	if C.libvlc_media_parse_with_options(this.ptr, C.libvlc_media_parse_flag_t(opts.Flags), C.int(timeout)) != 0 {
+		return PSNone, checkError()
+	}
+
+	// Parsing may have finished before libvlc returned.
+	if s := this.ParsedStatus(); s != PSNone {
+		return s, nil
+	}
+
+	for {
+		select {
+		case evt := <-events:
+			if p, ok := evt.Payload().(*ParsedChangedEvent); ok && p.Status != PSNone {
+				return p.Status, nil
+			}
+
+		case <-ctx.Done():
+			C.libvlc_media_parse_stop(this.ptr)
+			return ParsedStatus(C.libvlc_media_get_parsed_status(this.ptr)), ctx.Err()
+		}
+	}
+}
+
+// ParsedStatus returns the status of the last parse request.
+func (this *Media) ParsedStatus() ParsedStatus {
+	if this.ptr != nil {
+		return ParsedStatus(C.libvlc_media_get_parsed_status(this.ptr))
+	}
+	return PSNone
+}
+
 // IsParsed returns true if the media's metadata has already been parsed.
 func (this *Media) IsParsed() bool {
 	if this.ptr != nil {
@@ -278,6 +495,8 @@ func (this *Media) SetUserData(v interface{}) {
 //
 // This is very likely to change in next release, and will be done at the
 // parsing phase instead.
+//
+// Deprecated: Use Media.Tracks(), which works after parsing.
 func (this *Media) TrackInfo() ([]*TrackInfo, error) {
 	if this.ptr == nil {
 		return nil, &VLCError{"Media is nil"}
@@ -299,6 +518,104 @@ func (this *Media) TrackInfo() ([]*TrackInfo, error) {
 	return nil, checkError()
 }
 
+// Tracks yields the media descriptor's elementary streams. Each element is
+// an *AudioTrack, *VideoTrack or *SubtitleTrack, or a plain *Track for
+// streams of unknown type.
+//
+// The media needs to be parsed first, e.g. with Media.ParseContext(), or the
+// list will be empty.
+func (this *Media) Tracks() ([]MediaTrack, error) {
+	if this.ptr == nil {
+		return nil, &VLCError{"Media is nil"}
+	}
+
+	var c **C.libvlc_media_track_t
+	n := C.libvlc_media_tracks_get(this.ptr, &c)
+	if n == 0 {
+		return nil, nil
+	}
+
+	defer C.libvlc_media_tracks_release(c, n)
+
+	list := make([]MediaTrack, n)
+	for i, t := range unsafe.Slice(c, n) {
+		list[i] = newMediaTrack(t)
+	}
+
+	return list, nil
+}
+
+// An external track attached to a media. See Media.AddSlave().
+type Slave struct {
+	Type     SlaveType
+	Priority uint
+	URI      string
+}
+
+// Maximum slave priority. Slaves added by the user should use this, as lower
+// priorities are used by libvlc for slaves it detects automatically.
+const MaxSlavePriority = 4
+
+// AddSlave attaches an external subtitle or audio track to the media. The
+// uri must be a valid URI, e.g. "file:///home/me/dub.ogg" or
+// "http://example.com/sub.srt", and the priority ranges from 0 to
+// MaxSlavePriority.
+//
+// Slaves must be added before the media is played. Use Player.AddSlave() to
+// add one during playback.
+func (this *Media) AddSlave(kind SlaveType, priority uint, uri string) error {
+	if this.ptr == nil {
+		return &VLCError{"Media is nil"}
+	}
+
+	if priority > MaxSlavePriority {
+		return &VLCError{fmt.Sprintf("Invalid slave priority: %d", priority)}
+	}
+
+	c := C.CString(uri)
+	defer C.free(unsafe.Pointer(c))
+
+	if C.libvlc_media_slaves_add(this.ptr, C.libvlc_media_slave_type_t(kind), C.uint(priority), c) != 0 {
+		return &VLCError{"Failed to add slave: " + uri}
+	}
+
+	return nil
+}
+
+// ClearSlaves removes all slaves added with Media.AddSlave() or found by
+// libvlc while parsing.
+func (this *Media) ClearSlaves() error {
+	if this.ptr == nil {
+		return &VLCError{"Media is nil"}
+	}
+
+	C.libvlc_media_slaves_clear(this.ptr)
+	return nil
+}
+
+// Slaves returns the slaves added with Media.AddSlave(), along with those
+// found by libvlc while parsing.
+func (this *Media) Slaves() ([]*Slave, error) {
+	if this.ptr == nil {
+		return nil, &VLCError{"Media is nil"}
+	}
+
+	var c **C.libvlc_media_slave_t
+	n := C.libvlc_media_slaves_get(this.ptr, &c)
+	if n == 0 {
+		return nil, nil
+	}
+
+	defer C.libvlc_media_slaves_release(c, n)
+
+	list := make([]*Slave, n)
+	for i, s := range unsafe.Slice(c, n) {
+		list[i] = &Slave{SlaveType(s.i_type), uint(s.i_priority), C.GoString(s.psz_uri)}
+	}
+
+	return list, nil
+}
+
 // NewPlayer a media player from this media instance.
 // After creating the player, you can destroy this Media instance, unless you
 // really need it for something. It is not necessary to perform actual playback.
@@ -308,7 +625,7 @@ func (this *Media) NewPlayer() (*Player, error) {
 	}
 
 	if c := C.libvlc_media_player_new_from_media(this.ptr); c != nil {
-		return &Player{c}, nil
+		return newPlayer(c), nil
 	}
 
 	return nil, checkError()
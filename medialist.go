@@ -10,6 +10,14 @@ import "C"
 // Maintains a list of Media items.
 type MediaList struct {
 	ptr *C.libvlc_media_list_t
+	ref ref
+}
+
+// newMediaList wraps a list reference owned by the caller.
+func newMediaList(c *C.libvlc_media_list_t) *MediaList {
+	l := &MediaList{ptr: c}
+	l.ref.own(l, "MediaList")
+	return l
 }
 
 // Retain increments the reference count of this MediaList instance.
@@ -19,6 +27,7 @@ func (this *MediaList) Retain() error {
 	}
 
 	C.libvlc_media_list_retain(this.ptr)
+	this.ref.retain()
 	return checkError()
 }
 
@@ -30,10 +39,23 @@ func (this *MediaList) Release() error {
 	}
 
 	C.libvlc_media_list_release(this.ptr)
-	this.ptr = nil
+	if this.ref.release(this) {
+		this.ptr = nil
+	}
 	return checkError()
 }
 
+// Close gives up every reference this handle holds on the list. Media taken
+// from the list stay valid through their own handles. Calling Close again
+// does nothing.
+func (this *MediaList) Close() error {
+	if this.ptr != nil {
+		this.ref.close(this, func() { C.libvlc_media_list_release(this.ptr) })
+		this.ptr = nil
+	}
+	return nil
+}
+
 // Set associates a media instance with this media list.
 // If another media instance was present it will be released.
 //
@@ -57,7 +79,7 @@ func (this *MediaList) Get() (*Media, error) {
 	}
 
 	if c := C.libvlc_media_list_media(this.ptr); c != nil {
-		return &Media{c}, nil
+		return newMedia(c), nil
 	}
 
 	return nil, checkError()
@@ -120,7 +142,7 @@ func (this *MediaList) At(pos int) (*Media, error) {
 	}
 
 	if c := C.libvlc_media_list_item_at_index(this.ptr, C.int(pos)); c != nil {
-		return &Media{c}, nil
+		return newMedia(c), nil
 	}
 
 	return nil, checkError()
@@ -178,3 +200,15 @@ func (this *MediaList) Events() (*EventManager, error) {
 
 	return nil, checkError()
 }
+
+// Subscribe delivers the given MediaList* events of this list on a channel.
+// Item events carry the media and its index in a ListItemEvent. It is short
+// for Events() followed by EventManager.Subscribe().
+func (this *MediaList) Subscribe(types ...EventType) (<-chan *Event, func(), error) {
+	em, err := this.Events()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return em.Subscribe(types...)
+}
@@ -0,0 +1,307 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+// Package metrics exports playback statistics in the Prometheus text
+// exposition format.
+//
+// A Collector samples Media.Stats() of its registered players at a fixed
+// interval, and serves the latest samples over HTTP:
+//
+//	c := metrics.NewCollector(10 * time.Second)
+//	defer c.Close()
+//
+//	c.Register("lobby", metrics.Wrap(player))
+//	http.Handle("/metrics", c)
+//
+// All series are labeled with the player name and the MRL of its media.
+// libvlc resets the statistics when a player's media changes, which
+// Prometheus handles as a counter reset.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	vlc "github.com/jteeuwen/go-vlc"
+)
+
+// An exported statistic.
+type metric struct {
+	name  string
+	help  string
+	kind  string // "counter" or "gauge".
+	value func(s Stats) float64
+}
+
+// libvlc reports bitrates in bytes per microsecond.
+const bitsPerSecond = 8e6
+
+var metrics = []metric{
+	{"vlc_input_read_bytes_total", "Bytes read from the input.", "counter",
+		func(s Stats) float64 { return float64(s.ReadBytes()) }},
+	{"vlc_input_bits_per_second", "Input bitrate.", "gauge",
+		func(s Stats) float64 { return float64(s.InputBitRate()) * bitsPerSecond }},
+	{"vlc_demux_read_bytes_total", "Bytes read by the demuxer.", "counter",
+		func(s Stats) float64 { return float64(s.DemuxReadBytes()) }},
+	{"vlc_demux_bits_per_second", "Demuxer bitrate.", "gauge",
+		func(s Stats) float64 { return float64(s.DemuxBitRate()) * bitsPerSecond }},
+	{"vlc_demux_corrupted_total", "Corrupted packets found by the demuxer.", "counter",
+		func(s Stats) float64 { return float64(s.DemuxCorrupted()) }},
+	{"vlc_demux_discontinuity_total", "Discontinuities found by the demuxer.", "counter",
+		func(s Stats) float64 { return float64(s.DemuxDiscontinuity()) }},
+	{"vlc_decoded_video_total", "Video blocks decoded.", "counter",
+		func(s Stats) float64 { return float64(s.DecodedVideo()) }},
+	{"vlc_decoded_audio_total", "Audio blocks decoded.", "counter",
+		func(s Stats) float64 { return float64(s.DecodedAudio()) }},
+	{"vlc_displayed_pictures_total", "Pictures displayed.", "counter",
+		func(s Stats) float64 { return float64(s.DisplayedPictures()) }},
+	{"vlc_lost_pictures_total", "Pictures lost before display.", "counter",
+		func(s Stats) float64 { return float64(s.LostPictures()) }},
+	{"vlc_played_audio_buffers_total", "Audio buffers played.", "counter",
+		func(s Stats) float64 { return float64(s.PlayedAudioBuffers()) }},
+	{"vlc_lost_audio_buffers_total", "Audio buffers lost before playback.", "counter",
+		func(s Stats) float64 { return float64(s.LostAudioBuffers()) }},
+	{"vlc_sent_packets_total", "Packets sent by the stream output.", "counter",
+		func(s Stats) float64 { return float64(s.SentPackets()) }},
+	{"vlc_sent_bytes_total", "Bytes sent by the stream output.", "counter",
+		func(s Stats) float64 { return float64(s.SentBytes()) }},
+	{"vlc_sent_bits_per_second", "Stream output bitrate.", "gauge",
+		func(s Stats) float64 { return float64(s.SendBitRate()) * bitsPerSecond }},
+}
+
+// Statistics of a media, as reported by *vlc.Stats.
+type Stats interface {
+	ReadBytes() int
+	InputBitRate() float32
+	DemuxReadBytes() int
+	DemuxBitRate() float32
+	DemuxCorrupted() int
+	DemuxDiscontinuity() int
+	DecodedVideo() int
+	DecodedAudio() int
+	DisplayedPictures() int
+	LostPictures() int
+	PlayedAudioBuffers() int
+	LostAudioBuffers() int
+	SentPackets() int
+	SentBytes() int
+	SendBitRate() float32
+}
+
+// A player sampled by a Collector. Wrap adapts a *vlc.Player; tests may
+// register a fake instead.
+type Player interface {
+	IsPlaying() bool
+
+	// Stats returns the MRL of the current media and its statistics. The
+	// MRL is empty without media, and the statistics are nil if libvlc has
+	// none.
+	Stats() (mrl string, s Stats)
+}
+
+// Wrap returns p as a Player.
+func Wrap(p *vlc.Player) Player { return vlcPlayer{p} }
+
+type vlcPlayer struct{ *vlc.Player }
+
+func (this vlcPlayer) Stats() (string, Stats) {
+	m, err := this.Media()
+	if err != nil || m == nil {
+		return "", nil
+	}
+
+	defer m.Release()
+
+	st, err := m.Stats()
+	if err != nil {
+		return m.Mrl(), nil
+	}
+
+	return m.Mrl(), st
+}
+
+// Statistics of a player at one point in time.
+type sample struct {
+	player  string
+	mrl     string
+	playing bool
+	values  []float64 // In the order of metrics, or nil if unavailable.
+}
+
+// A registered player. Registrations are told apart by the entry's address,
+// as players need not be comparable.
+type entry struct {
+	player Player
+}
+
+// Samples the statistics of players and serves them over HTTP.
+type Collector struct {
+	lock     sync.Mutex
+	players  map[string]*entry
+	samples  map[string]*sample
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewCollector creates a collector which samples its players at the given
+// interval, until it is closed. Zero selects 10 seconds.
+func NewCollector(interval time.Duration) *Collector {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	c := &Collector{
+		players:  make(map[string]*entry),
+		samples:  make(map[string]*sample),
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go c.run()
+	return c
+}
+
+// Register adds a player under the given name, replacing any player with
+// the same name. It is sampled right away.
+func (this *Collector) Register(name string, p Player) {
+	s := sampleOf(name, p)
+
+	this.lock.Lock()
+	this.players[name] = &entry{p}
+	this.samples[name] = s
+	this.lock.Unlock()
+}
+
+// Unregister removes the player with the given name. Its series disappear
+// from the output.
+func (this *Collector) Unregister(name string) {
+	this.lock.Lock()
+	delete(this.players, name)
+	delete(this.samples, name)
+	this.lock.Unlock()
+}
+
+// Close stops sampling. The latest samples are still served.
+func (this *Collector) Close() error {
+	select {
+	case <-this.stop:
+	default:
+		close(this.stop)
+	}
+
+	<-this.done
+	return nil
+}
+
+func (this *Collector) run() {
+	defer close(this.done)
+
+	tick := time.NewTicker(this.interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			this.sample()
+		case <-this.stop:
+			return
+		}
+	}
+}
+
+// sample updates the samples of all players.
+func (this *Collector) sample() {
+	this.lock.Lock()
+	players := make(map[string]*entry, len(this.players))
+	for name, e := range this.players {
+		players[name] = e
+	}
+	this.lock.Unlock()
+
+	for name, e := range players {
+		s := sampleOf(name, e.player)
+
+		this.lock.Lock()
+		if this.players[name] == e {
+			this.samples[name] = s
+		}
+		this.lock.Unlock()
+	}
+}
+
+func sampleOf(name string, p Player) *sample {
+	s := &sample{player: name, playing: p.IsPlaying()}
+
+	mrl, st := p.Stats()
+	s.mrl = mrl
+
+	if st != nil {
+		s.values = make([]float64, len(metrics))
+		for i, v := range metrics {
+			s.values[i] = v.value(st)
+		}
+	}
+
+	return s
+}
+
+// ServeHTTP writes the latest samples in the Prometheus text format.
+func (this *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	this.WriteTo(w)
+}
+
+// WriteTo writes the latest samples in the Prometheus text format.
+func (this *Collector) WriteTo(w io.Writer) (int64, error) {
+	this.lock.Lock()
+	list := make([]*sample, 0, len(this.samples))
+	for _, s := range this.samples {
+		list = append(list, s)
+	}
+	this.lock.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].player < list[j].player })
+
+	var buf bytes.Buffer
+	writeSamples(&buf, list)
+	return buf.WriteTo(w)
+}
+
+func writeSamples(w io.Writer, list []*sample) {
+	fmt.Fprintf(w, "# HELP vlc_playing Whether the player is playing.\n")
+	fmt.Fprintf(w, "# TYPE vlc_playing gauge\n")
+
+	for _, s := range list {
+		v := 0
+		if s.playing {
+			v = 1
+		}
+		fmt.Fprintf(w, "vlc_playing%s %d\n", labels(s), v)
+	}
+
+	for i, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.kind)
+
+		for _, s := range list {
+			if s.values != nil {
+				fmt.Fprintf(w, "%s%s %g\n", m.name, labels(s), s.values[i])
+			}
+		}
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labels(s *sample) string {
+	return fmt.Sprintf(`{player="%s",mrl="%s"}`, labelEscaper.Replace(s.player), labelEscaper.Replace(s.mrl))
+}
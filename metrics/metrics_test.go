@@ -0,0 +1,166 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteSamples(t *testing.T) {
+	values := make([]float64, len(metrics))
+	for i, m := range metrics {
+		if m.name == "vlc_lost_pictures_total" {
+			values[i] = 3
+		}
+	}
+
+	list := []*sample{
+		{player: "lobby", mrl: `udp://@239.0.0.1:1234?name="a\b"`, playing: true, values: values},
+		{player: "stage"},
+	}
+
+	var buf bytes.Buffer
+	writeSamples(&buf, list)
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE vlc_playing gauge\n",
+		`vlc_playing{player="lobby",mrl="udp://@239.0.0.1:1234?name=\"a\\b\""} 1` + "\n",
+		`vlc_playing{player="stage",mrl=""} 0` + "\n",
+		"# TYPE vlc_lost_pictures_total counter\n",
+		`vlc_lost_pictures_total{player="lobby",mrl="udp://@239.0.0.1:1234?name=\"a\\b\""} 3` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	if strings.Contains(out, `vlc_lost_pictures_total{player="stage"`) {
+		t.Fatalf("player without statistics has series:\n%s", out)
+	}
+}
+
+// A fake player whose statistics report lost pictures only.
+type fakePlayer struct {
+	m       sync.Mutex
+	mrl     string
+	lost    int
+	samples int
+}
+
+func (this *fakePlayer) IsPlaying() bool { return true }
+
+func (this *fakePlayer) Stats() (string, Stats) {
+	this.m.Lock()
+	defer this.m.Unlock()
+
+	this.samples++
+	return this.mrl, fakeStats{lost: this.lost}
+}
+
+func (this *fakePlayer) set(lost int) {
+	this.m.Lock()
+	this.lost = lost
+	this.m.Unlock()
+}
+
+func (this *fakePlayer) count() int {
+	this.m.Lock()
+	defer this.m.Unlock()
+	return this.samples
+}
+
+type fakeStats struct{ lost int }
+
+func (fakeStats) ReadBytes() int          { return 0 }
+func (fakeStats) InputBitRate() float32   { return 0 }
+func (fakeStats) DemuxReadBytes() int     { return 0 }
+func (fakeStats) DemuxBitRate() float32   { return 0 }
+func (fakeStats) DemuxCorrupted() int     { return 0 }
+func (fakeStats) DemuxDiscontinuity() int { return 0 }
+func (fakeStats) DecodedVideo() int       { return 0 }
+func (fakeStats) DecodedAudio() int       { return 0 }
+func (fakeStats) DisplayedPictures() int  { return 0 }
+func (this fakeStats) LostPictures() int  { return this.lost }
+func (fakeStats) PlayedAudioBuffers() int { return 0 }
+func (fakeStats) LostAudioBuffers() int   { return 0 }
+func (fakeStats) SentPackets() int        { return 0 }
+func (fakeStats) SentBytes() int          { return 0 }
+func (fakeStats) SendBitRate() float32    { return 0 }
+
+func collected(c *Collector) string {
+	var buf bytes.Buffer
+	c.WriteTo(&buf)
+	return buf.String()
+}
+
+func TestCollector(t *testing.T) {
+	c := NewCollector(time.Millisecond)
+	defer c.Close()
+
+	p := &fakePlayer{mrl: "a", lost: 1}
+	c.Register("lobby", p)
+
+	// Registered players are sampled right away.
+	if out := collected(c); !strings.Contains(out, `vlc_lost_pictures_total{player="lobby",mrl="a"} 1`) {
+		t.Fatalf("output after Register:\n%s", out)
+	}
+
+	// And then by the sampling loop.
+	p.set(2)
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		if strings.Contains(collected(c), `vlc_lost_pictures_total{player="lobby",mrl="a"} 2`) {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("sampling loop did not pick up new statistics:\n%s", collected(c))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	c.Unregister("lobby")
+	if out := collected(c); strings.Contains(out, `player="lobby"`) {
+		t.Fatalf("output after Unregister:\n%s", out)
+	}
+
+	// Closing stops the sampling loop.
+	q := &fakePlayer{mrl: "b"}
+	c.Register("stage", q)
+	c.Close()
+
+	n := q.count()
+	time.Sleep(10 * time.Millisecond)
+	if have := q.count(); have != n {
+		t.Fatalf("samples after Close: have %d, want %d", have, n)
+	}
+
+	if out := collected(c); !strings.Contains(out, `vlc_playing{player="stage",mrl="b"} 1`) {
+		t.Fatalf("output after Close:\n%s", out)
+	}
+}
+
+// A player which is not comparable, as it is registered by value.
+type mapPlayer struct{ tags map[string]string }
+
+func (mapPlayer) IsPlaying() bool { return false }
+
+func (this mapPlayer) Stats() (string, Stats) { return this.tags["mrl"], nil }
+
+func TestCollectorMapPlayer(t *testing.T) {
+	c := NewCollector(time.Hour)
+	defer c.Close()
+
+	c.Register("lobby", mapPlayer{map[string]string{"mrl": "a"}})
+	c.sample()
+
+	if out := collected(c); !strings.Contains(out, `vlc_playing{player="lobby",mrl="a"} 0`) {
+		t.Fatalf("output after sample:\n%s", out)
+	}
+}
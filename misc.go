@@ -8,30 +8,37 @@ package vlc
 import "C"
 import (
 	"unsafe"
-)
-
-
-type VLCError struct {
-	What string
-}
-
-func (e *VLCError) Error() string {
-	return e.What
-}
 
+	"github.com/jteeuwen/go-vlc/vlcapi"
+)
 
+// Error returned for invalid use of a handle. It is defined in package vlcapi.
+type VLCError = vlcapi.VLCError
 
 // Medis discovery service.
 type Discoverer struct {
 	ptr *C.libvlc_media_discoverer_t
+	ref ref
+}
+
+// newDiscoverer wraps a discoverer owned by the caller.
+func newDiscoverer(c *C.libvlc_media_discoverer_t) *Discoverer {
+	d := &Discoverer{ptr: c}
+	d.ref.own(d, "Discoverer")
+	return d
 }
 
 // Release media discover object
-func (this *Discoverer) Release() {
+func (this *Discoverer) Release() { this.Close() }
+
+// Close stops and destroys the discoverer, like Release, but may be called
+// any number of times.
+func (this *Discoverer) Close() error {
 	if this.ptr != nil {
-		C.libvlc_media_discoverer_release(this.ptr)
+		this.ref.close(this, func() { C.libvlc_media_discoverer_release(this.ptr) })
 		this.ptr = nil
 	}
+	return nil
 }
 
 // LocalizedName return the localzied discovery service name.
@@ -56,7 +63,7 @@ func (this *Discoverer) MediaList() (m *MediaList, err error) {
 	}
 
 	if c := C.libvlc_media_discoverer_media_list(this.ptr); c != nil {
-		return &MediaList{c}, nil
+		return newMediaList(c), nil
 	}
 
 	return nil, checkError()
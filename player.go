@@ -9,17 +9,80 @@ package vlc
 // extern void* goLockCB(void*, void**);
 // extern void  goUnlockCB(void*, void*, void* const*); 
 // extern void  goDisplayCB(void*, void*);
+// extern void  goAudioPlayCB(void*, void*, unsigned, int64_t);
+// extern void  goAudioPauseCB(void*, int64_t);
+// extern void  goAudioResumeCB(void*, int64_t);
+// extern void  goAudioFlushCB(void*, int64_t);
+// extern void  goAudioDrainCB(void*);
+// extern void  goAudioVolumeCB(void*, float, int);
+// extern unsigned goFrameSetupCB(void**, char*, unsigned*, unsigned*, unsigned*, unsigned*);
+// extern void     goFrameCleanupCB(void*);
+// extern void*    goFrameLockCB(void*, void**);
+// extern void     goFrameDisplayCB(void*, void*);
+// extern void     goFrameTimeCB(const struct libvlc_event_t*, void*);
+// extern unsigned goVideoSetupCB(void**, char*, unsigned*, unsigned*, unsigned*, unsigned*);
+// extern void     goVideoCleanupCB(void*);
 //
-// static void goSetCallbacks(libvlc_media_player_t* mp, void* userdata) {
-//    libvlc_video_set_callbacks(mp, goLockCB, goUnlockCB, goDisplayCB, userdata);
+// static void goSetFormatCallbacks(libvlc_media_player_t* mp, int format) {
+//    if (format)
+//        libvlc_video_set_format_callbacks(mp, goVideoSetupCB, goVideoCleanupCB);
+//    else
+//        libvlc_video_set_format_callbacks(mp, NULL, NULL);
+// }
+// static void goSetCallbacks(libvlc_media_player_t* mp, uintptr_t userdata, int format) {
+//    libvlc_video_set_callbacks(mp, goLockCB, goUnlockCB, goDisplayCB, (void*)userdata);
+//    goSetFormatCallbacks(mp, format);
+// }
+// static void goAudioPlay(void* data, const void* samples, unsigned count, int64_t pts) {
+//    goAudioPlayCB(data, (void*)samples, count, pts);
+// }
+// static void goAudioVolume(void* data, float volume, bool mute) {
+//    goAudioVolumeCB(data, volume, mute ? 1 : 0);
+// }
+// static void goSetAudioCallbacks(libvlc_media_player_t* mp, uintptr_t userdata) {
+//    libvlc_audio_set_callbacks(mp, goAudioPlay, goAudioPauseCB, goAudioResumeCB,
+//                               goAudioFlushCB, goAudioDrainCB, (void*)userdata);
+// }
+// static void goSetAudioVolume(libvlc_media_player_t* mp, int set) {
+//    libvlc_audio_set_volume_callback(mp, set ? goAudioVolume : NULL);
+// }
+// static void goUnsetCallbacks(libvlc_media_player_t* mp, int video, int audio) {
+//    libvlc_media_player_stop(mp);
+//    if (video) {
+//        libvlc_video_set_callbacks(mp, NULL, NULL, NULL, NULL);
+//        libvlc_video_set_format_callbacks(mp, NULL, NULL);
+//    }
+//    if (audio) {
+//        libvlc_audio_set_callbacks(mp, NULL, NULL, NULL, NULL, NULL, NULL);
+//        libvlc_audio_set_volume_callback(mp, NULL);
+//    }
+// }
+// static void goSetFrameSink(libvlc_media_player_t* mp, uintptr_t userdata) {
+//    libvlc_video_set_callbacks(mp, goFrameLockCB, NULL, goFrameDisplayCB, (void*)userdata);
+//    libvlc_video_set_format_callbacks(mp, goFrameSetupCB, goFrameCleanupCB);
+//    libvlc_event_attach(libvlc_media_player_event_manager(mp), libvlc_MediaPlayerTimeChanged,
+//                        goFrameTimeCB, (void*)userdata);
 // }
 import "C"
 import (
+	"sync"
+	"time"
 	"unsafe"
 )
 
 type Player struct {
-	ptr *C.libvlc_media_player_t
+	ptr   *C.libvlc_media_player_t
+	ref   ref
+	cb    sync.Mutex // Guards audio and video.
+	audio *audioReq
+	video *memRenderReq
+}
+
+// newPlayer wraps a player reference owned by the caller.
+func newPlayer(c *C.libvlc_media_player_t) *Player {
+	p := &Player{ptr: c}
+	p.ref.own(p, "Player")
+	return p
 }
 
 // Retain increments the reference count of this player.
@@ -29,6 +92,7 @@ func (this *Player) Retain() (err error) {
 	}
 
 	C.libvlc_media_player_retain(this.ptr)
+	this.ref.retain()
 	return
 }
 
@@ -39,10 +103,32 @@ func (this *Player) Release() (err error) {
 		return &VLCError{"Player is nil"}
 	}
 
+	last := this.ref.release(this)
+	if last {
+		this.unregister()
+	}
+
 	C.libvlc_media_player_release(this.ptr)
+	if last {
+		this.ptr = nil
+	}
 	return
 }
 
+// Close gives up every reference this handle holds on the player, and
+// removes the audio and video callbacks set through it. If any were set,
+// playback is stopped first, as other references may keep the player alive.
+// Otherwise playback stops once libvlc destroys the player. Calling Close
+// again does nothing.
+func (this *Player) Close() error {
+	if this.ptr != nil {
+		this.unregister()
+		this.ref.close(this, func() { C.libvlc_media_player_release(this.ptr) })
+		this.ptr = nil
+	}
+	return nil
+}
+
 // Media returns the media currently associated with this player.
 func (this *Player) Media() (*Media, error) {
 	if this.ptr == nil {
@@ -50,7 +136,7 @@ func (this *Player) Media() (*Media, error) {
 	}
 
 	if c := C.libvlc_media_player_get_media(this.ptr); c != nil {
-		return &Media{c}, nil
+		return newMedia(c), nil
 	}
 
 	return nil, checkError()
@@ -80,6 +166,19 @@ func (this *Player) Events() (*EventManager, error) {
 	return nil, checkError()
 }
 
+// Subscribe delivers the given MediaPlayer* events of this player on a
+// channel. Each state change has an event of its own, such as
+// MediaPlayerPlaying, with a StateChangedEvent payload. It is short for
+// Events() followed by EventManager.Subscribe().
+func (this *Player) Subscribe(types ...EventType) (<-chan *Event, func(), error) {
+	em, err := this.Events()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return em.Subscribe(types...)
+}
+
 // IsPlaying returns whether or not this player is currently playing.
 func (this *Player) IsPlaying() bool {
 	if this.ptr == nil {
@@ -139,8 +238,8 @@ func (this *Player) Stop() (err error) {
 }
 
 // SetCallbacks set callbacks and private data to render decoded video to a
-// custom area in memory. Use libvlc_video_set_format() to configure the decoded
-// format.
+// custom area in memory. Use Player.SetFormat() to configure a fixed decoded
+// format, or Player.SetFormatCallbacks() to negotiate it with the source.
 //
 // Whenever a new video frame needs to be decoded, the lock callback is
 // invoked. Depending on the video chroma, one or three pixel planes of
@@ -159,7 +258,53 @@ func (this *Player) SetCallbacks(lh LockHandler, uh UnlockHandler, dh DisplayHan
 		return &VLCError{"Player is nil"}
 	}
 
-	C.goSetCallbacks(this.ptr, unsafe.Pointer(&memRenderReq{lh, uh, dh, userdata}))
+	req := this.videoReq()
+	req.m.Lock()
+	req.lh, req.uh, req.dh = lh, uh, dh
+	req.ud = userdata
+	var format C.int
+	if req.fh != nil {
+		format = 1
+	}
+	req.m.Unlock()
+
+	C.goSetCallbacks(this.ptr, C.uintptr_t(req.id), format)
+	return
+}
+
+// SetFormatCallbacks sets callbacks to negotiate the decoded video format with
+// the source. This only works in combination with Player.SetCallbacks(), which
+// supplies the userdata passed to both handlers, and replaces any format set
+// with Player.SetFormat().
+//
+// The format handler is invoked whenever the video output is set up, including
+// each time the source resolution changes. It receives the source chroma and
+// dimensions, and describes the pictures it wants decoded by changing them and
+// filling in the pitch and line count of every plane. The cleanup handler,
+// which may be nil, is invoked when the video output is torn down.
+//
+// Passing a nil format handler reverts to the format set with
+// Player.SetFormat().
+func (this *Player) SetFormatCallbacks(fh FormatHandler, ch CleanupHandler) (err error) {
+	if this.ptr == nil {
+		return &VLCError{"Player is nil"}
+	}
+
+	if fh == nil && ch != nil {
+		return &VLCError{"Cleanup handler requires a format handler"}
+	}
+
+	req := this.videoReq()
+	req.m.Lock()
+	req.fh, req.ch = fh, ch
+	req.m.Unlock()
+
+	var format C.int
+	if fh != nil {
+		format = 1
+	}
+
+	C.goSetFormatCallbacks(this.ptr, format)
 	return
 }
 
@@ -182,6 +327,173 @@ func (this *Player) SetFormat(chroma string, width, height, pitch uint) error {
 	return nil
 }
 
+// FrameSink makes the player render decoded video into Go images, which are
+// delivered on the returned sink's channel. Buffer is the number of frames
+// the channel holds before new frames are dropped.
+//
+// This replaces any callbacks set with Player.SetCallbacks(). Use
+// FrameSink.SetFormat() to choose the chroma and dimensions, and close the
+// sink after playback has stopped. The sink keeps the player alive until it
+// is closed.
+func (this *Player) FrameSink(buffer int) (*FrameSink, error) {
+	if this.ptr == nil {
+		return nil, &VLCError{"Player is nil"}
+	}
+
+	sink := newFrameSink(buffer)
+	sink.id = cbRegister(sink)
+	sink.mp = this.ptr
+	C.libvlc_media_player_retain(this.ptr)
+
+	C.goSetFrameSink(this.ptr, C.uintptr_t(sink.id))
+	return sink, nil
+}
+
+// SetAudioCallbacks sets callbacks to receive decoded audio samples instead
+// of sending them to an audio output. Use Player.SetAudioFormat() to configure
+// the decoded sample format.
+//
+// The play handler receives a copy of the interleaved PCM samples, along with
+// the format they are in and their presentation timestamp in microseconds. It
+// is required. The other handlers may be nil; pause and resume are invoked when
+// playback is paused or resumed, flush when queued samples should be discarded
+// (e.g. on seek) and drain when playback is about to end and all pending
+// samples should be played out.
+//
+// All handlers are invoked from a libvlc thread.
+func (this *Player) SetAudioCallbacks(ph AudioPlayHandler, pah AudioPauseHandler, rh AudioResumeHandler, fh AudioFlushHandler, dh AudioDrainHandler, userdata interface{}) error {
+	if this.ptr == nil {
+		return &VLCError{"Player is nil"}
+	}
+
+	if ph == nil {
+		return &VLCError{"Audio play handler is nil"}
+	}
+
+	req := this.audioReq()
+	req.m.Lock()
+	req.ph, req.pah, req.rh, req.fh, req.dh = ph, pah, rh, fh, dh
+	req.ud = userdata
+	req.m.Unlock()
+
+	C.goSetAudioCallbacks(this.ptr, C.uintptr_t(req.id))
+	return nil
+}
+
+// SetAudioVolumeCallback sets a handler which is invoked when the software
+// volume or mute state changes while Player.SetAudioCallbacks() is in use.
+// While a handler is set, libvlc no longer applies the volume to the samples
+// itself; specify nil to have it do so again. Changes take effect the next
+// time playback starts.
+func (this *Player) SetAudioVolumeCallback(vh AudioVolumeHandler) error {
+	if this.ptr == nil {
+		return &VLCError{"Player is nil"}
+	}
+
+	req := this.audioReq()
+	req.m.Lock()
+	req.vh = vh
+	req.m.Unlock()
+
+	var set C.int
+	if vh != nil {
+		set = 1
+	}
+
+	C.goSetAudioVolume(this.ptr, set)
+	return nil
+}
+
+// SetAudioFormat specifies the decoded audio format. This only works in
+// combination with Player.SetAudioCallbacks(). The default is "S16N" at
+// 44100 Hz with 2 channels.
+//
+// The format parameter should be a four-character string identifying the
+// sample format (e.g. "S16N", "S32N" or "FL32"). Rate is the sample rate in
+// Hz and channels the number of interleaved channels.
+func (this *Player) SetAudioFormat(format string, rate, channels uint) error {
+	if this.ptr == nil {
+		return &VLCError{"Player is nil"}
+	}
+
+	af := AudioFormat{format, rate, channels}
+	if af.SampleSize() == 0 {
+		return &VLCError{"Unsupported audio format: " + format}
+	}
+
+	if channels == 0 || rate == 0 {
+		return &VLCError{"Invalid audio rate or channel count"}
+	}
+
+	req := this.audioReq()
+	req.m.Lock()
+	req.format = af
+	req.m.Unlock()
+
+	c := C.CString(format)
+	C.libvlc_audio_set_format(this.ptr, c, C.uint(rate), C.uint(channels))
+	C.free(unsafe.Pointer(c))
+	return nil
+}
+
+// audioReq returns the audio callback state for this player, creating it
+// if necessary.
+func (this *Player) audioReq() *audioReq {
+	this.cb.Lock()
+	defer this.cb.Unlock()
+
+	if this.audio == nil {
+		this.audio = &audioReq{format: AudioFormat{"S16N", 44100, 2}}
+		this.audio.id = cbRegister(this.audio)
+	}
+	return this.audio
+}
+
+// videoReq returns the player's video callback state, creating it if needed.
+func (this *Player) videoReq() *memRenderReq {
+	this.cb.Lock()
+	defer this.cb.Unlock()
+
+	if this.video == nil {
+		this.video = new(memRenderReq)
+		this.video.id = cbRegister(this.video)
+	}
+	return this.video
+}
+
+// unregister removes the player's callback state from the callback registry
+// when its last reference is given up. The player may outlive it, so the
+// callbacks are first removed from libvlc, which only drops them once the
+// audio and video outputs are torn down by stopping playback.
+func (this *Player) unregister() {
+	this.cb.Lock()
+	defer this.cb.Unlock()
+
+	if this.audio == nil && this.video == nil {
+		return
+	}
+
+	var video, audio C.int
+	if this.video != nil {
+		video = 1
+	}
+	if this.audio != nil {
+		audio = 1
+	}
+
+	C.goUnsetCallbacks(this.ptr, video, audio)
+
+	if this.audio != nil {
+		cbUnregister(this.audio.id)
+		this.audio = nil
+	}
+
+	if this.video != nil {
+		cbUnregister(this.video.id)
+		this.video = nil
+	}
+}
+
 // SetNSObject sets the NSView handler where the media player should render its
 // video output.
 //
@@ -333,6 +645,103 @@ func (this *Player) SetPosition(v float32) {
 	}
 }
 
+// Elapsed returns the current movie time.
+func (this *Player) Elapsed() (time.Duration, error) {
+	t, err := this.Time()
+	return time.Duration(t) * time.Millisecond, err
+}
+
+// MediaLength returns the length of the current movie. It is zero if the
+// length is not known yet.
+func (this *Player) MediaLength() (time.Duration, error) {
+	l, err := this.Length()
+	if l < 0 {
+		l = 0
+	}
+	return time.Duration(l) * time.Millisecond, err
+}
+
+// Seek jumps to the movie time d. Unlike SetTime(), it returns an error if
+// the current media can not be seeked in, as reported by CanSeek().
+//
+// libVLC 3.0 picks the seek mode when playback starts, from the one set with
+// Media.SetSeekMode(). If mode differs from it, an error is returned rather
+// than seeking in the other mode.
+func (this *Player) Seek(d time.Duration, mode SeekMode) error {
+	if d < 0 {
+		return &VLCError{"Seek time is negative"}
+	}
+
+	if err := this.checkSeek(mode); err != nil {
+		return err
+	}
+
+	C.libvlc_media_player_set_time(this.ptr, C.libvlc_time_t(d/time.Millisecond))
+	return checkError()
+}
+
+// SeekBy jumps d forward from the current movie time, or backward if d is
+// negative. Seeking back past the start jumps to the start. See Seek().
+func (this *Player) SeekBy(d time.Duration, mode SeekMode) error {
+	t, err := this.Elapsed()
+	if err != nil {
+		return err
+	}
+
+	if t += d; t < 0 {
+		t = 0
+	}
+
+	return this.Seek(t, mode)
+}
+
+// SeekPosition jumps to the movie position pos, between 0.0 and 1.0. Unlike
+// SetPosition(), it returns an error like Seek() does.
+func (this *Player) SeekPosition(pos float32, mode SeekMode) error {
+	if pos < 0 || pos > 1 {
+		return &VLCError{"Seek position out of range"}
+	}
+
+	if err := this.checkSeek(mode); err != nil {
+		return err
+	}
+
+	C.libvlc_media_player_set_position(this.ptr, C.float(pos))
+	return checkError()
+}
+
+// checkSeek returns an error if the current media can not be seeked in with
+// the given mode.
+func (this *Player) checkSeek(mode SeekMode) error {
+	if this.ptr == nil {
+		return &VLCError{"Player is nil"}
+	}
+
+	if ok, err := this.CanSeek(); err != nil {
+		return err
+	} else if !ok {
+		return &VLCError{"Media is not seekable"}
+	}
+
+	m, err := this.Media()
+	if err != nil {
+		return err
+	}
+
+	if m == nil {
+		return &VLCError{"Player has no media"}
+	}
+
+	have := m.SeekMode()
+	m.Release()
+
+	if have != mode {
+		return &VLCError{"Media is set up for " + have.String() + " seeking, not " + mode.String()}
+	}
+
+	return nil
+}
+
 // ChapterCount returns the number of available movie chapters.
 func (this *Player) ChapterCount() (int, error) {
 	if this.ptr == nil {
@@ -723,6 +1132,27 @@ func (this *Player) SetSubtitleFile(path string) (err error) {
 	return checkError()
 }
 
+// AddSlave adds an external subtitle or audio track to the media currently
+// being played. The uri must be a valid URI, e.g. "file:///home/me/sub.srt".
+// If sel is true, the new track is selected once it has been loaded.
+//
+// Unlike Media.AddSlave(), this takes effect during playback. If nothing is
+// playing, the slave is added to the current media instead.
+func (this *Player) AddSlave(kind SlaveType, uri string, sel bool) error {
+	if this.ptr == nil {
+		return &VLCError{"Player is nil"}
+	}
+
+	c := C.CString(uri)
+	defer C.free(unsafe.Pointer(c))
+
+	if C.libvlc_media_player_add_slave(this.ptr, C.libvlc_media_slave_type_t(kind), c, C.bool(sel)) != 0 {
+		return &VLCError{"Failed to add slave: " + uri}
+	}
+
+	return nil
+}
+
 // ChapterDescription returns descriptions of available chapters for a specific title.
 //
 // Note: make sure to call TrackDescriptionList.Release() when you are done with it.
@@ -1167,6 +1597,31 @@ func (this *Player) SetVolume(v int) error {
 	return checkError()
 }
 
+// SetEqualizer applies the equalizer settings to the player's audio output.
+// Passing nil disables the equalizer.
+//
+// The player copies the settings, so the equalizer may be released or changed
+// afterwards. Changes only take effect when SetEqualizer is called again.
+func (this *Player) SetEqualizer(eq *Equalizer) error {
+	if this.ptr == nil {
+		return &VLCError{"Player is nil"}
+	}
+
+	var c *C.libvlc_equalizer_t
+	if eq != nil {
+		if eq.ptr == nil {
+			return &VLCError{"Equalizer is nil"}
+		}
+		c = eq.ptr
+	}
+
+	if C.libvlc_media_player_set_equalizer(this.ptr, c) != 0 {
+		return &VLCError{"Failed to set equalizer"}
+	}
+
+	return nil
+}
+
 // AudioTrackCount returns the number of available audio tracks.
 func (this *Player) AudioTrackCount() (int, error) {
 	if this.ptr == nil {
@@ -1234,7 +1689,7 @@ func (this *Player) SetAudioChannel(channel int) (err error) {
 	return
 }
 
-// AudioDelay returns the current audio delay.
+// AudioDelay returns the current audio delay in microseconds.
 func (this *Player) AudioDelay() (int64, error) {
 	if this.ptr == nil {
 		return 0, &VLCError{"Player is nil"}
@@ -1242,7 +1697,7 @@ func (this *Player) AudioDelay() (int64, error) {
 	return int64(C.libvlc_audio_get_delay(this.ptr)), checkError()
 }
 
-// SetAudioDelay sets the current audio delay.
+// SetAudioDelay sets the current audio delay in microseconds.
 func (this *Player) SetAudioDelay(delay int64) (err error) {
 	if this.ptr == nil {
 		return &VLCError{"Player is nil"}
@@ -1254,3 +1709,15 @@ func (this *Player) SetAudioDelay(delay int64) (err error) {
 
 	return
 }
+
+// AudioOffset returns the current audio delay.
+func (this *Player) AudioOffset() (time.Duration, error) {
+	d, err := this.AudioDelay()
+	return time.Duration(d) * time.Microsecond, err
+}
+
+// SetAudioOffset sets the audio delay. A positive offset plays audio later
+// than video. It is reset to zero when the media changes.
+func (this *Player) SetAudioOffset(d time.Duration) error {
+	return this.SetAudioDelay(int64(d / time.Microsecond))
+}
@@ -0,0 +1,624 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Playlist file formats.
+type PlaylistFormat int
+
+const (
+	PlaylistM3U  PlaylistFormat = iota // M3U and M3U8, with EXTINF extensions.
+	PlaylistPLS                        // Shoutcast/Winamp PLS. Holds no options and only titles.
+	PlaylistXSPF                       // XML Shareable Playlist Format.
+)
+
+// PlaylistFormatOf returns the playlist format for the given file name or
+// URI, based on its extension.
+func PlaylistFormatOf(name string) (PlaylistFormat, bool) {
+	if i := strings.IndexAny(name, "?#"); i >= 0 && strings.Contains(name, "://") {
+		name = name[:i]
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".m3u", ".m3u8":
+		return PlaylistM3U, true
+	case ".pls":
+		return PlaylistPLS, true
+	case ".xspf":
+		return PlaylistXSPF, true
+	}
+
+	return 0, false
+}
+
+// A single playlist item.
+type PlaylistEntry struct {
+	Location string                  // Local path or URI.
+	Duration time.Duration           // Zero if unknown.
+	Options  []string                // Media options, as passed to Media.AddOption().
+	Meta     map[MetaProperty]string // Title, artist, album and so on.
+}
+
+// Metadata properties supported by at least one format.
+var playlistMeta = []MetaProperty{
+	MPTitle, MPArtist, MPAlbum, MPGenre, MPTrackNumber, MPDescription, MPArtworkURL,
+}
+
+// Import reads a playlist in the given format and appends its items to the
+// list. Relative locations are resolved against base, the path or URI of
+// the playlist itself; an empty base leaves them unchanged.
+//
+// Options and metadata are applied to each media. libvlc determines the
+// duration itself and offers no way to set it, so the entries which were
+// appended are returned as well, in list order, with their locations
+// resolved and the durations the playlist gives for them. If an entry fails
+// to import, those before it are returned along with the error.
+func (this *MediaList) Import(inst *Instance, r io.Reader, format PlaylistFormat, base string) ([]*PlaylistEntry, error) {
+	if this.ptr == nil {
+		return nil, &VLCError{"MediaList is nil"}
+	}
+
+	if inst == nil || inst.ptr == nil {
+		return nil, &VLCError{"Instance is nil"}
+	}
+
+	entries, err := ReadPlaylist(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = this.Lock(); err != nil {
+		return nil, err
+	}
+
+	defer this.Unlock()
+
+	for i, e := range entries {
+		if err = this.importEntry(inst, e, base); err != nil {
+			return entries[:i], err
+		}
+	}
+
+	return entries, nil
+}
+
+func (this *MediaList) importEntry(inst *Instance, e *PlaylistEntry, base string) (err error) {
+	var m *Media
+
+	e.Location = resolveLocation(e.Location, base)
+	if strings.Contains(e.Location, "://") {
+		m, err = inst.OpenMediaUri(e.Location)
+	} else {
+		m, err = inst.OpenMediaFile(e.Location)
+	}
+
+	if err != nil {
+		return
+	}
+
+	// The list holds its own reference.
+	defer m.Release()
+
+	for _, o := range e.Options {
+		if err = m.AddOption(o); err != nil {
+			return
+		}
+	}
+
+	for k, v := range e.Meta {
+		m.SetMeta(k, v)
+	}
+
+	return this.Add(m)
+}
+
+// Export writes the list in the given format. Each item's location,
+// duration, options and those metadata properties the format supports are
+// written. The metadata of items which have not been parsed may be
+// incomplete; see Media.Meta().
+func (this *MediaList) Export(w io.Writer, format PlaylistFormat) error {
+	if this.ptr == nil {
+		return &VLCError{"MediaList is nil"}
+	}
+
+	if err := this.Lock(); err != nil {
+		return err
+	}
+
+	entries, err := this.entries()
+	this.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return WritePlaylist(w, format, entries)
+}
+
+func (this *MediaList) entries() ([]*PlaylistEntry, error) {
+	count, err := this.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*PlaylistEntry, 0, count)
+
+	for i := 0; i < count; i++ {
+		m, err := this.At(i)
+		if err != nil {
+			return nil, err
+		}
+
+		e := &PlaylistEntry{
+			Location: m.Mrl(),
+			Options:  m.Options(),
+			Meta:     make(map[MetaProperty]string),
+		}
+
+		if d := m.Duration(); d > 0 {
+			e.Duration = time.Duration(d) * time.Millisecond
+		}
+
+		for _, k := range playlistMeta {
+			if v := m.Meta(k); v != "" {
+				e.Meta[k] = v
+			}
+		}
+
+		m.Release()
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// ReadPlaylist parses a playlist in the given format. Locations are
+// returned as written.
+func ReadPlaylist(r io.Reader, format PlaylistFormat) ([]*PlaylistEntry, error) {
+	switch format {
+	case PlaylistM3U:
+		return readM3U(r)
+	case PlaylistPLS:
+		return readPLS(r)
+	case PlaylistXSPF:
+		return readXSPF(r)
+	}
+
+	return nil, &VLCError{fmt.Sprintf("Unknown playlist format: %d", format)}
+}
+
+// WritePlaylist writes entries as a playlist in the given format. M3U and
+// PLS playlists get local paths instead of file:// URIs, as not all
+// players understand them. XSPF playlists get URIs only.
+func WritePlaylist(w io.Writer, format PlaylistFormat, entries []*PlaylistEntry) error {
+	bw := bufio.NewWriter(w)
+
+	switch format {
+	case PlaylistM3U:
+		writeM3U(bw, entries)
+	case PlaylistPLS:
+		writePLS(bw, entries)
+	case PlaylistXSPF:
+		writeXSPF(bw, entries)
+	default:
+		return &VLCError{fmt.Sprintf("Unknown playlist format: %d", format)}
+	}
+
+	return bw.Flush()
+}
+
+func newPlaylistEntry() *PlaylistEntry {
+	return &PlaylistEntry{Meta: make(map[MetaProperty]string)}
+}
+
+// resolveLocation resolves a relative location against the playlist's own
+// location.
+func resolveLocation(loc, base string) string {
+	if base == "" || strings.Contains(loc, "://") || filepath.IsAbs(loc) {
+		return loc
+	}
+
+	if strings.Contains(base, "://") {
+		b, err := url.Parse(base)
+		if err != nil {
+			return loc
+		}
+
+		r, err := url.Parse(loc)
+		if err != nil {
+			return loc
+		}
+
+		return b.ResolveReference(r).String()
+	}
+
+	// Relative XSPF locations are URI references.
+	if p, err := url.PathUnescape(loc); err == nil {
+		loc = p
+	}
+
+	return filepath.Join(filepath.Dir(base), filepath.FromSlash(loc))
+}
+
+// localPath returns the path of a file:// URI, or the location unchanged.
+func localPath(loc string) string {
+	if !strings.HasPrefix(loc, "file://") {
+		return loc
+	}
+
+	u, err := url.Parse(loc)
+	if err != nil || u.Path == "" {
+		return loc
+	}
+
+	return filepath.FromSlash(u.Path)
+}
+
+// uriLocation returns a local path as a file:// URI, or a URI reference if
+// it is relative. URIs are returned unchanged.
+func uriLocation(loc string) string {
+	if strings.Contains(loc, "://") {
+		return loc
+	}
+
+	u := &url.URL{Path: filepath.ToSlash(loc)}
+	if filepath.IsAbs(loc) {
+		u.Scheme = "file"
+		if !strings.HasPrefix(u.Path, "/") {
+			u.Path = "/" + u.Path // Windows drive letters.
+		}
+	}
+
+	return u.String()
+}
+
+// seconds formats a duration in whole seconds, or -1 if it is unknown.
+func seconds(d time.Duration) string {
+	if d <= 0 {
+		return "-1"
+	}
+	return strconv.FormatInt(int64((d+time.Second/2)/time.Second), 10)
+}
+
+// parseSeconds parses a duration in seconds. Negative values mean unknown.
+func parseSeconds(s string) time.Duration {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v <= 0 {
+		return 0
+	}
+	return time.Duration(v * float64(time.Second))
+}
+
+// lines reads r line by line, without line endings or a leading UTF-8 byte
+// order mark. Lines which are not valid UTF-8 are assumed to be Latin-1, as
+// is common in .m3u and .pls files.
+func lines(r io.Reader, fn func(string)) error {
+	sc := bufio.NewScanner(r)
+
+	for first := true; sc.Scan(); first = false {
+		s := strings.TrimRight(sc.Text(), "\r")
+
+		if first {
+			s = strings.TrimPrefix(s, "\ufeff")
+		}
+
+		if !utf8.ValidString(s) {
+			rs := make([]rune, len(s))
+			for i := 0; i < len(s); i++ {
+				rs[i] = rune(s[i])
+			}
+			s = string(rs)
+		}
+
+		fn(strings.TrimSpace(s))
+	}
+
+	return sc.Err()
+}
+
+func readM3U(r io.Reader) ([]*PlaylistEntry, error) {
+	var list []*PlaylistEntry
+	e := newPlaylistEntry()
+
+	err := lines(r, func(s string) {
+		switch {
+		case s == "":
+
+		case strings.HasPrefix(s, "#EXTINF:"):
+			parseEXTINF(e, s[len("#EXTINF:"):])
+		case strings.HasPrefix(s, "#EXTVLCOPT:"):
+			e.Options = append(e.Options, s[len("#EXTVLCOPT:"):])
+		case strings.HasPrefix(s, "#EXTALB:"):
+			e.Meta[MPAlbum] = strings.TrimSpace(s[len("#EXTALB:"):])
+		case strings.HasPrefix(s, "#EXTART:"):
+			e.Meta[MPArtist] = strings.TrimSpace(s[len("#EXTART:"):])
+		case strings.HasPrefix(s, "#EXTGENRE:"):
+			e.Meta[MPGenre] = strings.TrimSpace(s[len("#EXTGENRE:"):])
+		case strings.HasPrefix(s, "#"):
+			// Other comments and extensions.
+
+		default:
+			e.Location = s
+			list = append(list, e)
+			e = newPlaylistEntry()
+		}
+	})
+
+	return list, err
+}
+
+// parseEXTINF parses "<seconds>[ attributes],[<artist> - ]<title>". Commas
+// in quoted attribute values do not end the attributes.
+func parseEXTINF(e *PlaylistEntry, s string) {
+	end := len(s)
+	quoted := false
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			quoted = !quoted
+		} else if s[i] == ',' && !quoted {
+			end = i
+			break
+		}
+	}
+
+	head := strings.TrimSpace(s[:end])
+	if i := strings.IndexAny(head, " \t"); i >= 0 {
+		head = head[:i]
+	}
+
+	e.Duration = parseSeconds(head)
+
+	if end >= len(s) {
+		return
+	}
+
+	title := strings.TrimSpace(s[end+1:])
+	if i := strings.Index(title, " - "); i >= 0 {
+		e.Meta[MPArtist] = strings.TrimSpace(title[:i])
+		title = strings.TrimSpace(title[i+3:])
+	}
+
+	if title != "" {
+		e.Meta[MPTitle] = title
+	}
+}
+
+func writeM3U(w *bufio.Writer, entries []*PlaylistEntry) {
+	w.WriteString("#EXTM3U\n")
+
+	for _, e := range entries {
+		title := e.Meta[MPTitle]
+		if a := e.Meta[MPArtist]; a != "" {
+			title = a + " - " + title
+		}
+
+		if title != "" || e.Duration > 0 {
+			fmt.Fprintf(w, "#EXTINF:%s,%s\n", seconds(e.Duration), title)
+		}
+
+		if v := e.Meta[MPAlbum]; v != "" {
+			fmt.Fprintf(w, "#EXTALB:%s\n", v)
+		}
+
+		if v := e.Meta[MPGenre]; v != "" {
+			fmt.Fprintf(w, "#EXTGENRE:%s\n", v)
+		}
+
+		for _, o := range e.Options {
+			fmt.Fprintf(w, "#EXTVLCOPT:%s\n", strings.TrimPrefix(o, ":"))
+		}
+
+		fmt.Fprintf(w, "%s\n", localPath(e.Location))
+	}
+}
+
+func readPLS(r io.Reader) ([]*PlaylistEntry, error) {
+	byIndex := make(map[int]*PlaylistEntry)
+	section := ""
+
+	err := lines(r, func(s string) {
+		if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+			section = strings.ToLower(s[1 : len(s)-1])
+			return
+		}
+
+		kv := strings.SplitN(s, "=", 2)
+		if section != "playlist" || len(kv) != 2 {
+			return
+		}
+
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		value := strings.TrimSpace(kv[1])
+
+		// Keys are of the form FileN, TitleN and LengthN.
+		i := strings.IndexAny(key, "0123456789")
+		if i < 0 {
+			return
+		}
+
+		n, err := strconv.Atoi(key[i:])
+		if err != nil {
+			return
+		}
+
+		e, ok := byIndex[n]
+		if !ok {
+			e = newPlaylistEntry()
+			byIndex[n] = e
+		}
+
+		switch key[:i] {
+		case "file":
+			e.Location = value
+		case "title":
+			e.Meta[MPTitle] = value
+		case "length":
+			e.Duration = parseSeconds(value)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]int, 0, len(byIndex))
+	for n, e := range byIndex {
+		if e.Location != "" {
+			keys = append(keys, n)
+		}
+	}
+
+	sort.Ints(keys)
+
+	list := make([]*PlaylistEntry, len(keys))
+	for i, n := range keys {
+		list[i] = byIndex[n]
+	}
+
+	return list, nil
+}
+
+func writePLS(w *bufio.Writer, entries []*PlaylistEntry) {
+	w.WriteString("[playlist]\n")
+
+	for i, e := range entries {
+		n := i + 1
+		fmt.Fprintf(w, "File%d=%s\n", n, localPath(e.Location))
+
+		if v := e.Meta[MPTitle]; v != "" {
+			fmt.Fprintf(w, "Title%d=%s\n", n, v)
+		}
+
+		fmt.Fprintf(w, "Length%d=%s\n", n, seconds(e.Duration))
+	}
+
+	fmt.Fprintf(w, "NumberOfEntries=%d\nVersion=2\n", len(entries))
+}
+
+const (
+	xspfNamespace = "http://xspf.org/ns/0/"
+	vlcNamespace  = "http://www.videolan.org/vlc/playlist/ns/0/"
+	vlcExtension  = "http://www.videolan.org/vlc/playlist/0"
+)
+
+type xspfTrack struct {
+	Location   []string `xml:"location"`
+	Title      string   `xml:"title"`
+	Creator    string   `xml:"creator"`
+	Album      string   `xml:"album"`
+	TrackNum   string   `xml:"trackNum"`
+	Annotation string   `xml:"annotation"`
+	Image      string   `xml:"image"`
+	Duration   int64    `xml:"duration"`
+	Extension  []struct {
+		Application string   `xml:"application,attr"`
+		Options     []string `xml:"option"`
+	} `xml:"extension"`
+}
+
+func readXSPF(r io.Reader) ([]*PlaylistEntry, error) {
+	var doc struct {
+		XMLName xml.Name    `xml:"playlist"`
+		Tracks  []xspfTrack `xml:"trackList>track"`
+	}
+
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, &VLCError{"Invalid XSPF playlist: " + err.Error()}
+	}
+
+	var list []*PlaylistEntry
+
+	for _, t := range doc.Tracks {
+		if len(t.Location) == 0 {
+			continue
+		}
+
+		e := newPlaylistEntry()
+		e.Location = strings.TrimSpace(t.Location[0])
+
+		if t.Duration > 0 {
+			e.Duration = time.Duration(t.Duration) * time.Millisecond
+		}
+
+		for k, v := range map[MetaProperty]string{
+			MPTitle:       t.Title,
+			MPArtist:      t.Creator,
+			MPAlbum:       t.Album,
+			MPTrackNumber: t.TrackNum,
+			MPDescription: t.Annotation,
+			MPArtworkURL:  t.Image,
+		} {
+			if v = strings.TrimSpace(v); v != "" {
+				e.Meta[k] = v
+			}
+		}
+
+		for _, x := range t.Extension {
+			if x.Application == vlcExtension {
+				e.Options = append(e.Options, x.Options...)
+			}
+		}
+
+		list = append(list, e)
+	}
+
+	return list, nil
+}
+
+func writeXSPF(w *bufio.Writer, entries []*PlaylistEntry) {
+	elem := func(indent, name, value string) {
+		if value == "" {
+			return
+		}
+
+		fmt.Fprintf(w, "%s<%s>", indent, name)
+		xml.EscapeText(w, []byte(value))
+		fmt.Fprintf(w, "</%s>\n", name)
+	}
+
+	w.WriteString(xml.Header)
+	fmt.Fprintf(w, "<playlist xmlns=%q xmlns:vlc=%q version=\"1\">\n", xspfNamespace, vlcNamespace)
+	w.WriteString("\t<trackList>\n")
+
+	for i, e := range entries {
+		w.WriteString("\t\t<track>\n")
+		elem("\t\t\t", "location", uriLocation(e.Location))
+		elem("\t\t\t", "title", e.Meta[MPTitle])
+		elem("\t\t\t", "creator", e.Meta[MPArtist])
+		elem("\t\t\t", "album", e.Meta[MPAlbum])
+		elem("\t\t\t", "trackNum", e.Meta[MPTrackNumber])
+		elem("\t\t\t", "annotation", e.Meta[MPDescription])
+		elem("\t\t\t", "image", e.Meta[MPArtworkURL])
+
+		if e.Duration > 0 {
+			elem("\t\t\t", "duration", strconv.FormatInt(int64(e.Duration/time.Millisecond), 10))
+		}
+
+		fmt.Fprintf(w, "\t\t\t<extension application=%q>\n", vlcExtension)
+		elem("\t\t\t\t", "vlc:id", strconv.Itoa(i))
+
+		for _, o := range e.Options {
+			elem("\t\t\t\t", "vlc:option", strings.TrimPrefix(o, ":"))
+		}
+
+		w.WriteString("\t\t\t</extension>\n\t\t</track>\n")
+	}
+
+	w.WriteString("\t</trackList>\n</playlist>\n")
+}
@@ -0,0 +1,201 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// entry returns a playlist entry with the given metadata, as key/value pairs.
+func entry(loc string, d time.Duration, options []string, meta ...interface{}) *PlaylistEntry {
+	e := &PlaylistEntry{Location: loc, Duration: d, Options: options, Meta: make(map[MetaProperty]string)}
+	for i := 0; i < len(meta); i += 2 {
+		e.Meta[meta[i].(MetaProperty)] = meta[i+1].(string)
+	}
+	return e
+}
+
+func checkEntries(t *testing.T, name string, have, want []*PlaylistEntry) {
+	t.Helper()
+
+	if len(have) != len(want) {
+		t.Fatalf("%s: have %d entries, want %d", name, len(have), len(want))
+	}
+
+	for i := range want {
+		if !reflect.DeepEqual(have[i], want[i]) {
+			t.Fatalf("%s: entry %d:\nhave %+v\nwant %+v", name, i, have[i], want[i])
+		}
+	}
+}
+
+func TestParseEXTINF(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want *PlaylistEntry
+	}{
+		{"123,Artist - Title", entry("", 123*time.Second, nil, MPArtist, "Artist", MPTitle, "Title")},
+		{"-1,Just a title", entry("", 0, nil, MPTitle, "Just a title")},
+		{"3.5,", entry("", 3500*time.Millisecond, nil)},
+		{"42", entry("", 42*time.Second, nil)},
+		{"0,A - B - C", entry("", 0, nil, MPArtist, "A", MPTitle, "B - C")},
+		{"60,Live, Loud and Late", entry("", time.Minute, nil, MPTitle, "Live, Loud and Late")},
+		{`185 tvg-id="n1" group-title="News, Sports",Channel 1, HD`,
+			entry("", 185*time.Second, nil, MPTitle, "Channel 1, HD")},
+		{`-1 tvg-logo="a,b.png" tvg-name="x",Band - Song, Part 2`,
+			entry("", 0, nil, MPArtist, "Band", MPTitle, "Song, Part 2")},
+	} {
+		e := newPlaylistEntry()
+		parseEXTINF(e, tt.in)
+
+		if !reflect.DeepEqual(e, tt.want) {
+			t.Fatalf("parseEXTINF(%s):\nhave %+v\nwant %+v", tt.in, e, tt.want)
+		}
+	}
+}
+
+func TestReadPlaylist(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		format PlaylistFormat
+		in     string
+		want   []*PlaylistEntry
+	}{
+		{"m3u", PlaylistM3U, "\ufeff#EXTM3U\r\n" +
+			"#EXTINF:125,Artist - Title\r\n" +
+			"#EXTALB:Album\r\n" +
+			"#EXTGENRE:Rock\r\n" +
+			"#EXTVLCOPT:start-time=10\r\n" +
+			"#EXTVLCOPT:no-video\r\n" +
+			"music/a.mp3\r\n" +
+			"\r\n" +
+			"# A comment\r\n" +
+			"#EXTINF:-1 tvg-id=\"x,y\",Radio, Live\n" +
+			"http://example.com/stream\n" +
+			"#EXTINF:10,Caf\xe9\n" +
+			"/srv/caf\xe9.ogg\n",
+			[]*PlaylistEntry{
+				entry("music/a.mp3", 125*time.Second, []string{"start-time=10", "no-video"},
+					MPArtist, "Artist", MPTitle, "Title", MPAlbum, "Album", MPGenre, "Rock"),
+				entry("http://example.com/stream", 0, nil, MPTitle, "Radio, Live"),
+				entry("/srv/café.ogg", 10*time.Second, nil, MPTitle, "Café"),
+			}},
+
+		{"pls", PlaylistPLS, "[playlist]\n" +
+			"File2=http://example.com/b\n" +
+			"Title2=Second\n" +
+			"Length2=-1\n" +
+			"file1 = /srv/a.ogg\n" +
+			"TITLE1=First\n" +
+			"Length1=61\n" +
+			"Title3=No file\n" +
+			"NumberOfEntries=3\n" +
+			"Version=2\n" +
+			"[other]\n" +
+			"File4=/ignored\n",
+			[]*PlaylistEntry{
+				entry("/srv/a.ogg", 61*time.Second, nil, MPTitle, "First"),
+				entry("http://example.com/b", 0, nil, MPTitle, "Second"),
+			}},
+
+		{"xspf", PlaylistXSPF, `<?xml version="1.0" encoding="UTF-8"?>
+<playlist xmlns="http://xspf.org/ns/0/" xmlns:vlc="http://www.videolan.org/vlc/playlist/ns/0/" version="1">
+	<trackList>
+		<track>
+			<location>file:///srv/a%20b.ogg</location>
+			<title>Title</title>
+			<creator>Artist</creator>
+			<trackNum>3</trackNum>
+			<duration>61500</duration>
+			<extension application="http://www.videolan.org/vlc/playlist/0">
+				<vlc:id>0</vlc:id>
+				<vlc:option>start-time=10</vlc:option>
+			</extension>
+			<extension application="http://example.com/other">
+				<vlc:option>ignored</vlc:option>
+			</extension>
+		</track>
+		<track>
+			<title>No location</title>
+		</track>
+		<track>
+			<location> http://example.com/b </location>
+		</track>
+	</trackList>
+</playlist>`,
+			[]*PlaylistEntry{
+				entry("file:///srv/a%20b.ogg", 61500*time.Millisecond, []string{"start-time=10"},
+					MPTitle, "Title", MPArtist, "Artist", MPTrackNumber, "3"),
+				entry("http://example.com/b", 0, nil),
+			}},
+	} {
+		have, err := ReadPlaylist(strings.NewReader(tt.in), tt.format)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		checkEntries(t, tt.name, have, tt.want)
+	}
+
+	if _, err := ReadPlaylist(strings.NewReader("<playlist>"), PlaylistXSPF); err == nil {
+		t.Fatal("xspf: expected error for truncated document")
+	}
+}
+
+func TestWritePlaylist(t *testing.T) {
+	entries := []*PlaylistEntry{
+		entry("file:///srv/a%20b.ogg", 61500*time.Millisecond, []string{":start-time=10"},
+			MPArtist, "Artist", MPTitle, "Title, Part 1", MPAlbum, "Album"),
+		entry("http://example.com/b", 0, nil),
+	}
+
+	for _, tt := range []struct {
+		format PlaylistFormat
+		want   string
+	}{
+		{PlaylistM3U, "#EXTM3U\n" +
+			"#EXTINF:62,Artist - Title, Part 1\n" +
+			"#EXTALB:Album\n" +
+			"#EXTVLCOPT:start-time=10\n" +
+			"/srv/a b.ogg\n" +
+			"http://example.com/b\n"},
+
+		{PlaylistPLS, "[playlist]\n" +
+			"File1=/srv/a b.ogg\n" +
+			"Title1=Title, Part 1\n" +
+			"Length1=62\n" +
+			"File2=http://example.com/b\n" +
+			"Length2=-1\n" +
+			"NumberOfEntries=2\n" +
+			"Version=2\n"},
+	} {
+		var buf bytes.Buffer
+		if err := WritePlaylist(&buf, tt.format, entries); err != nil {
+			t.Fatal(err)
+		}
+
+		if have := buf.String(); have != tt.want {
+			t.Fatalf("format %d:\nhave %q\nwant %q", tt.format, have, tt.want)
+		}
+	}
+
+	// Everything XSPF holds survives a round trip.
+	var buf bytes.Buffer
+	if err := WritePlaylist(&buf, PlaylistXSPF, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	have, err := ReadPlaylist(&buf, PlaylistXSPF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries[0].Options[0] = "start-time=10"
+	checkEntries(t, "xspf", have, entries)
+}
@@ -0,0 +1,168 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jteeuwen/go-vlc/vlcapi"
+)
+
+// Events sent on the event stream.
+var (
+	playerEvents = []vlcapi.EventType{
+		vlcapi.MediaPlayerMediaChanged,
+		vlcapi.MediaPlayerNothingSpecial,
+		vlcapi.MediaPlayerOpening,
+		vlcapi.MediaPlayerBuffering,
+		vlcapi.MediaPlayerPlaying,
+		vlcapi.MediaPlayerPaused,
+		vlcapi.MediaPlayerStopped,
+		vlcapi.MediaPlayerEndReached,
+		vlcapi.MediaPlayerEncounteredError,
+		vlcapi.MediaPlayerTimeChanged,
+		vlcapi.MediaPlayerSeekableChanged,
+		vlcapi.MediaPlayerPausableChanged,
+		vlcapi.MediaPlayerLengthChanged,
+		vlcapi.MediaPlayerESSelected,
+		vlcapi.MediaPlayerMuted,
+		vlcapi.MediaPlayerUnmuted,
+		vlcapi.MediaPlayerAudioVolume,
+	}
+
+	listPlayerEvents = []vlcapi.EventType{
+		vlcapi.MediaListPlayerPlayed,
+		vlcapi.MediaListPlayerNextItemSet,
+		vlcapi.MediaListPlayerStopped,
+	}
+
+	listEvents = []vlcapi.EventType{
+		vlcapi.MediaListItemAdded,
+		vlcapi.MediaListItemDeleted,
+	}
+)
+
+// serveEvents streams events until the client goes away.
+func (this *Handler) serveEvents(w http.ResponseWriter, r *http.Request) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, &httpError{http.StatusInternalServerError, errors.New("Streaming is not supported")})
+		return
+	}
+
+	type source interface {
+		Subscribe(types ...vlcapi.EventType) (<-chan *vlcapi.Event, func(), error)
+	}
+
+	sources := []source{this.player}
+	types := [][]vlcapi.EventType{playerEvents}
+
+	if this.opts.ListPlayer != nil {
+		sources = append(sources, this.opts.ListPlayer)
+		types = append(types, listPlayerEvents)
+	}
+
+	if this.opts.List != nil {
+		sources = append(sources, this.opts.List)
+		types = append(types, listEvents)
+	}
+
+	ctx := r.Context()
+	events := make(chan *vlcapi.Event)
+
+	for i, s := range sources {
+		c, cancel, err := s.Subscribe(types[i]...)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		defer cancel()
+
+		go func() {
+			for evt := range c {
+				select {
+				case events <- evt:
+				case <-ctx.Done():
+					release(evt)
+					return
+				}
+			}
+		}()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+
+	keepAlive := time.NewTicker(this.opts.KeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case evt := <-events:
+			data, _ := json.Marshal(eventData(evt))
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, data)
+			release(evt)
+
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+
+		case <-ctx.Done():
+			return
+		}
+
+		f.Flush()
+	}
+}
+
+// release gives up the media which Subscribe() retained for evt.
+func release(evt *vlcapi.Event) {
+	if m := evt.Media(); m != nil {
+		m.Release()
+	}
+}
+
+// eventData returns the JSON form of an event's payload. Media handles are
+// left out.
+func eventData(evt *vlcapi.Event) map[string]interface{} {
+	d := make(map[string]interface{})
+
+	switch p := evt.Payload().(type) {
+	case *vlcapi.StateChangedEvent:
+		d["state"] = p.State.String()
+	case *vlcapi.BufferingEvent:
+		d["cache"] = p.Cache
+	case *vlcapi.TimeChangedEvent:
+		d["time"] = p.Time.Seconds()
+	case *vlcapi.LengthChangedEvent:
+		d["length"] = p.Length.Seconds()
+	case *vlcapi.SeekableChangedEvent:
+		d["seekable"] = p.Seekable
+	case *vlcapi.PausableChangedEvent:
+		d["pausable"] = p.Pausable
+	case *vlcapi.ESChangedEvent:
+		d["type"] = trackTypes[p.Type]
+		d["id"] = p.Id
+	case *vlcapi.AudioVolumeEvent:
+		d["volume"] = int(p.Volume*100 + 0.5)
+	case *vlcapi.ListItemEvent:
+		d["index"] = p.Index
+	}
+
+	return d
+}
+
+var trackTypes = map[vlcapi.TrackType]string{
+	vlcapi.TTUnknown: "unknown",
+	vlcapi.TTAudio:   "audio",
+	vlcapi.TTVideo:   "video",
+	vlcapi.TTText:    "subtitle",
+}
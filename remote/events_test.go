@@ -0,0 +1,128 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package remote
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jteeuwen/go-vlc/vlctest"
+)
+
+// readEvent reads the next event from an event stream, skipping comments.
+func readEvent(t *testing.T, r *bufio.Reader) (name, data string) {
+	t.Helper()
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event: %v", err)
+		}
+
+		switch line = strings.TrimSuffix(line, "\n"); {
+		case line == "":
+			if name != "" {
+				return name, data
+			}
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		default:
+			t.Fatalf("read event: unexpected line %q", line)
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	b := vlctest.NewBackend()
+	b.Register("a", vlctest.MediaInfo{Length: time.Minute})
+
+	p, _ := b.NewPlayer()
+	m, _ := b.OpenMediaUri("a")
+	p.SetMedia(m)
+
+	done := make(chan struct{}, 1)
+	h := NewHandler(p, Options{Token: "secret", KeepAlive: 10 * time.Millisecond})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r)
+		if strings.HasSuffix(r.URL.Path, "/events") {
+			done <- struct{}{}
+		}
+	}))
+	defer srv.Close()
+
+	for _, token := range []string{"", "?token=wrong"} {
+		resp, err := http.Get(srv.URL + "/events" + token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		<-done
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("events with %q: have %d, want %d", token, resp.StatusCode, http.StatusUnauthorized)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, _ := http.NewRequest("GET", srv.URL+"/events?token=secret", nil)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("events: have %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type: have %q, want text/event-stream", ct)
+	}
+
+	// The handler has subscribed by the time the headers arrive.
+	p.Play()
+
+	r := bufio.NewReader(resp.Body)
+	for _, want := range []struct{ name, data string }{
+		{"MediaPlayerOpening", `{"state":"opening"}`},
+		{"MediaPlayerLengthChanged", `{"length":60}`},
+		{"MediaPlayerSeekableChanged", `{"seekable":true}`},
+		{"MediaPlayerPausableChanged", `{"pausable":true}`},
+		{"MediaPlayerPlaying", `{"state":"playing"}`},
+	} {
+		if name, data := readEvent(t, r); name != want.name || data != want.data {
+			t.Fatalf("event: have %s %s, want %s %s", name, data, want.name, want.data)
+		}
+	}
+
+	// Keep-alive comments are sent while nothing happens.
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read keep-alive: %v", err)
+		}
+		if line == ": keep-alive\n" {
+			break
+		}
+	}
+
+	// The handler returns once the client goes away.
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("handler did not return after the client went away")
+	}
+}
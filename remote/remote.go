@@ -0,0 +1,494 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+// Package remote controls a player over HTTP. Handler serves a JSON API and
+// a stream of player events, to be mounted in any http.Server:
+//
+//	h := remote.NewHandler(player.API(), remote.Options{Token: "secret"})
+//	http.Handle("/player/", http.StripPrefix("/player", h))
+//
+// The API is:
+//
+//	GET    /status             Status of the player.
+//	POST   /play               Start or resume playback.
+//	POST   /pause              Toggle pause.
+//	POST   /stop               Stop playback.
+//	POST   /seek               Seek; body {"time": s}, {"offset": s} or {"position": p}.
+//	POST   /volume             Body {"volume": n} and/or {"muted": b}.
+//	GET    /tracks             Audio, video and subtitle tracks.
+//	POST   /tracks             Select a track; body {"type": "audio", "id": n}.
+//	GET    /playlist           Items of the playlist.
+//	POST   /playlist           Append an item; body {"mrl": "file:///a.ogg"}.
+//	DELETE /playlist/{index}   Remove an item.
+//	POST   /playlist/{index}   Play an item.
+//	POST   /next               Play the next item.
+//	POST   /prev               Play the previous item.
+//	GET    /events             Server-sent events.
+//
+// Times are in seconds. Control requests answer with the new Status, and
+// failures with {"error": "..."}. The playlist requests need a ListPlayer,
+// and the track requests a player which implements vlcapi.TrackAPI, such as
+// one from package vlc; otherwise they fail with 501 Not Implemented.
+//
+// The event stream carries player, list player and playlist events. Each
+// is named after its event type, e.g. "MediaPlayerPlaying", and its data
+// is a JSON object with the event's payload, such as {"state": "playing"}.
+package remote
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jteeuwen/go-vlc/vlcapi"
+)
+
+// Options of a Handler.
+type Options struct {
+	// If set, requests must carry this token, either in an
+	// "Authorization: Bearer" header or in a "token" query parameter. The
+	// latter is meant for EventSource clients, which can not set headers.
+	Token string
+
+	// Optional list player and its list, for the playlist requests. The
+	// list player must play with the player given to NewHandler().
+	ListPlayer vlcapi.ListPlayerAPI
+	List       vlcapi.MediaListAPI
+
+	// Opens the media added to the playlist. Required to add items.
+	Instance vlcapi.InstanceAPI
+
+	// Interval of keep-alive comments in the event stream. Zero selects
+	// 15 seconds.
+	KeepAlive time.Duration
+}
+
+// Status of a player, as returned by most requests.
+type Status struct {
+	State    string  `json:"state"`
+	Mrl      string  `json:"mrl,omitempty"`
+	Title    string  `json:"title,omitempty"`
+	Time     float64 `json:"time"`   // Seconds.
+	Length   float64 `json:"length"` // Seconds. Zero if unknown.
+	Position float32 `json:"position"`
+	Rate     float32 `json:"rate"`
+	Volume   int     `json:"volume"`
+	Muted    bool    `json:"muted"`
+	Seekable bool    `json:"seekable"`
+	Pausable bool    `json:"pausable"`
+}
+
+// An item of the playlist.
+type Item struct {
+	Index    int     `json:"index"`
+	Mrl      string  `json:"mrl"`
+	Title    string  `json:"title,omitempty"`
+	Duration float64 `json:"duration,omitempty"` // Seconds.
+	Current  bool    `json:"current"`            // Media of the player.
+}
+
+// Tracks of the current media.
+type Tracks struct {
+	Audio    []Track `json:"audio"`
+	Video    []Track `json:"video"`
+	Subtitle []Track `json:"subtitle"`
+}
+
+type Track struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Selected bool   `json:"selected"`
+}
+
+// Serves the remote control API for a player.
+type Handler struct {
+	player vlcapi.PlayerAPI
+	opts   Options
+}
+
+// NewHandler creates a handler which controls the given player.
+func NewHandler(p vlcapi.PlayerAPI, opts Options) *Handler {
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = 15 * time.Second
+	}
+
+	return &Handler{player: p, opts: opts}
+}
+
+// An error with its HTTP status.
+type httpError struct {
+	code int
+	err  error
+}
+
+func (this *httpError) Error() string { return this.err.Error() }
+
+var (
+	errNoPlaylist = &httpError{http.StatusNotImplemented, errors.New("No playlist")}
+	errNoTracks   = &httpError{http.StatusNotImplemented, errors.New("Player does not support track selection")}
+)
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Errorf(format, args...)}
+}
+
+func (this *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !this.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="vlc"`)
+		writeError(w, &httpError{http.StatusUnauthorized, errors.New("Unauthorized")})
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+
+	if path == "events" && r.Method == "GET" {
+		this.serveEvents(w, r)
+		return
+	}
+
+	var v interface{}
+	var err error
+
+	switch r.Method + " " + path {
+	case "GET status":
+		v, err = this.status()
+	case "POST play":
+		err = this.control((vlcapi.PlayerAPI).Play, (vlcapi.ListPlayerAPI).Play)
+	case "POST pause":
+		err = this.control((vlcapi.PlayerAPI).Pause, (vlcapi.ListPlayerAPI).Pause)
+	case "POST stop":
+		err = this.control((vlcapi.PlayerAPI).Stop, (vlcapi.ListPlayerAPI).Stop)
+	case "POST seek":
+		err = this.seek(r)
+	case "POST volume":
+		err = this.volume(r)
+	case "GET tracks":
+		v, err = this.tracks()
+	case "POST tracks":
+		err = this.selectTrack(r)
+	case "GET playlist":
+		v, err = this.playlist()
+	case "POST playlist":
+		err = this.add(r)
+	case "POST next":
+		err = this.control(nil, (vlcapi.ListPlayerAPI).Next)
+	case "POST prev":
+		err = this.control(nil, (vlcapi.ListPlayerAPI).Prev)
+
+	default:
+		if !strings.HasPrefix(path, "playlist/") {
+			writeError(w, &httpError{http.StatusNotFound, errors.New("Not found")})
+			return
+		}
+
+		i, perr := strconv.Atoi(strings.TrimPrefix(path, "playlist/"))
+		switch {
+		case perr != nil:
+			err = badRequest("Invalid index")
+		case r.Method == "DELETE":
+			err = this.remove(i)
+		case r.Method == "POST":
+			err = this.control(nil, func(lp vlcapi.ListPlayerAPI) error { return lp.PlayAt(i) })
+		default:
+			err = &httpError{http.StatusMethodNotAllowed, errors.New("Method not allowed")}
+		}
+	}
+
+	if err == nil && v == nil {
+		v, err = this.status()
+	}
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (this *Handler) authorized(r *http.Request) bool {
+	if this.opts.Token == "" {
+		return true
+	}
+
+	token := r.URL.Query().Get("token")
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		token = strings.TrimPrefix(h, "Bearer ")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(this.opts.Token)) == 1
+}
+
+func (this *Handler) status() (*Status, error) {
+	p := this.player
+
+	state, err := p.State()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Status{State: state.String()}
+
+	if m, err := p.Media(); err == nil && m != nil {
+		s.Mrl = m.Mrl()
+		s.Title = m.Meta(vlcapi.MPTitle)
+		m.Release()
+	}
+
+	t, _ := p.Elapsed()
+	l, _ := p.MediaLength()
+	s.Time, s.Length = t.Seconds(), l.Seconds()
+	s.Position, _ = p.Position()
+	s.Rate, _ = p.Rate()
+	s.Volume, _ = p.Volume()
+	s.Muted, _ = p.IsMute()
+	s.Seekable, _ = p.CanSeek()
+	s.Pausable, _ = p.CanPause()
+	return s, nil
+}
+
+// control calls pf on the player, or lpf on the list player if there is
+// one. If pf is nil, a list player is required.
+func (this *Handler) control(pf func(vlcapi.PlayerAPI) error, lpf func(vlcapi.ListPlayerAPI) error) error {
+	if lp := this.opts.ListPlayer; lp != nil {
+		return lpf(lp)
+	}
+
+	if pf == nil {
+		return errNoPlaylist
+	}
+
+	return pf(this.player)
+}
+
+func (this *Handler) seek(r *http.Request) error {
+	var req struct {
+		Time     *float64
+		Offset   *float64
+		Position *float64
+	}
+
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+
+	// Seek the way the media was set up for.
+	mode := vlcapi.SeekPrecise
+	if m, err := this.player.Media(); err == nil && m != nil {
+		mode = vlcapi.MediaSeekMode(m.Options())
+		m.Release()
+	}
+
+	switch {
+	case req.Time != nil:
+		return this.player.Seek(seconds(*req.Time), mode)
+
+	case req.Offset != nil:
+		return this.player.SeekBy(seconds(*req.Offset), mode)
+
+	case req.Position != nil:
+		if *req.Position < 0 || *req.Position > 1 {
+			return badRequest("Position out of range")
+		}
+
+		return this.player.SeekPosition(float32(*req.Position), mode)
+	}
+
+	return badRequest("Missing time, offset or position")
+}
+
+func (this *Handler) volume(r *http.Request) error {
+	var req struct {
+		Volume *int
+		Muted  *bool
+	}
+
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+
+	if req.Volume != nil {
+		if err := this.player.SetVolume(*req.Volume); err != nil {
+			return err
+		}
+	}
+
+	if req.Muted != nil {
+		return this.player.SetMute(*req.Muted)
+	}
+
+	return nil
+}
+
+func (this *Handler) tracks() (*Tracks, error) {
+	tp, ok := this.player.(vlcapi.TrackAPI)
+	if !ok {
+		return nil, errNoTracks
+	}
+
+	list := func(describe func() ([]vlcapi.Track, error), current func() (int, error)) []Track {
+		l, _ := describe()
+		cur, _ := current()
+
+		tracks := make([]Track, 0, len(l))
+		for _, d := range l {
+			tracks = append(tracks, Track{Id: d.Id, Name: d.Name, Selected: d.Id == cur})
+		}
+		return tracks
+	}
+
+	return &Tracks{
+		Audio:    list(tp.AudioTracks, tp.AudioTrack),
+		Video:    list(tp.VideoTracks, tp.VideoTrack),
+		Subtitle: list(tp.SubtitleTracks, tp.SubTile),
+	}, nil
+}
+
+func (this *Handler) selectTrack(r *http.Request) error {
+	tp, ok := this.player.(vlcapi.TrackAPI)
+	if !ok {
+		return errNoTracks
+	}
+
+	var req struct {
+		Type string
+		Id   int
+	}
+
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+
+	switch req.Type {
+	case "audio":
+		return tp.SetAudioTrack(req.Id)
+	case "video":
+		return tp.SetVideoTrack(req.Id)
+	case "subtitle":
+		return tp.SetSubtitle(req.Id)
+	}
+
+	return badRequest("Invalid track type %q", req.Type)
+}
+
+func (this *Handler) playlist() ([]Item, error) {
+	l := this.opts.List
+	if l == nil || this.opts.ListPlayer == nil {
+		return nil, errNoPlaylist
+	}
+
+	var cur string
+	if m, err := this.player.Media(); err == nil && m != nil {
+		cur = m.Mrl()
+		m.Release()
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	n, err := l.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, n)
+
+	for i := 0; i < n; i++ {
+		m, err := l.At(i)
+		if err != nil {
+			return nil, err
+		}
+
+		it := Item{Index: i, Mrl: m.Mrl(), Title: m.Meta(vlcapi.MPTitle)}
+		it.Current = it.Mrl == cur
+
+		if d, err := m.Length(); err == nil {
+			it.Duration = d.Seconds()
+		}
+
+		m.Release()
+		items = append(items, it)
+	}
+
+	return items, nil
+}
+
+func (this *Handler) add(r *http.Request) error {
+	l := this.opts.List
+	if l == nil || this.opts.ListPlayer == nil || this.opts.Instance == nil {
+		return errNoPlaylist
+	}
+
+	var req struct{ Mrl string }
+
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+
+	if req.Mrl == "" {
+		return badRequest("Missing mrl")
+	}
+
+	m, err := this.opts.Instance.OpenMediaUri(req.Mrl)
+	if err != nil {
+		return err
+	}
+
+	// The list keeps its own reference.
+	defer m.Release()
+
+	l.Lock()
+	defer l.Unlock()
+	return l.Add(m)
+}
+
+func (this *Handler) remove(i int) error {
+	l := this.opts.List
+	if l == nil || this.opts.ListPlayer == nil {
+		return errNoPlaylist
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	return l.Remove(i)
+}
+
+// decode reads a JSON request body into v.
+func decode(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(v); err != nil {
+		return badRequest("Invalid request body: %v", err)
+	}
+	return nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError reports err. Misuse of the player, such as seeking in media
+// which is not seekable, is a conflict with its state. Other errors, such as
+// those reported by libvlc, are internal server errors.
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch e := err.(type) {
+	case *httpError:
+		code = e.code
+	case *vlcapi.VLCError:
+		code = http.StatusConflict
+	}
+
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
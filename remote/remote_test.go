@@ -0,0 +1,133 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jteeuwen/go-vlc/vlcapi"
+	"github.com/jteeuwen/go-vlc/vlctest"
+)
+
+func do(t *testing.T, h http.Handler, method, path, body string, v interface{}) int {
+	t.Helper()
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer secret")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if v != nil && w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+
+	return w.Code
+}
+
+func TestHandler(t *testing.T) {
+	b := vlctest.NewBackend()
+	b.Register("a", vlctest.MediaInfo{Length: time.Minute})
+	b.Register("b", vlctest.MediaInfo{Length: time.Minute})
+
+	l, _ := b.NewList()
+	lp, _ := b.NewListPlayer()
+	p, _ := b.NewPlayer()
+	lp.Replace(p)
+	lp.Set(l)
+
+	h := NewHandler(p, Options{Token: "secret", ListPlayer: lp, List: l, Instance: b})
+
+	r := httptest.NewRequest("GET", "/status", nil)
+	w := httptest.NewRecorder()
+	if h.ServeHTTP(w, r); w.Code != http.StatusUnauthorized {
+		t.Fatalf("status without token: have %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	do(t, h, "POST", "/playlist", `{"mrl": "a"}`, nil)
+	do(t, h, "POST", "/playlist", `{"mrl": "b"}`, nil)
+
+	var s Status
+	if code := do(t, h, "POST", "/play", "", &s); code != http.StatusOK {
+		t.Fatalf("play: status %d", code)
+	}
+
+	if s.State != "playing" || s.Mrl != "a" || s.Length != 60 {
+		t.Fatalf("status: have %+v", s)
+	}
+
+	do(t, h, "POST", "/seek", `{"time": 30}`, &s)
+	do(t, h, "POST", "/seek", `{"offset": -10}`, &s)
+	if s.Time != 20 {
+		t.Fatalf("time: have %v, want 20", s.Time)
+	}
+
+	do(t, h, "POST", "/seek", `{"position": 0.5}`, &s)
+	if s.Time != 30 {
+		t.Fatalf("time: have %v, want 30", s.Time)
+	}
+
+	do(t, h, "POST", "/volume", `{"volume": 50, "muted": true}`, &s)
+	if s.Volume != 50 || !s.Muted {
+		t.Fatalf("volume: have %d, muted %v", s.Volume, s.Muted)
+	}
+
+	do(t, h, "POST", "/next", "", &s)
+	if s.Mrl != "b" {
+		t.Fatalf("next: have %q, want b", s.Mrl)
+	}
+
+	var items []Item
+	do(t, h, "GET", "/playlist", "", &items)
+	if len(items) != 2 || !items[1].Current || items[0].Duration != 60 {
+		t.Fatalf("playlist: have %+v", items)
+	}
+
+	do(t, h, "DELETE", "/playlist/0", "", nil)
+	do(t, h, "GET", "/playlist", "", &items)
+	if len(items) != 1 || items[0].Mrl != "b" {
+		t.Fatalf("playlist after delete: have %+v", items)
+	}
+
+	if code := do(t, h, "GET", "/tracks", "", nil); code != http.StatusNotImplemented {
+		t.Fatalf("tracks: have %d, want %d", code, http.StatusNotImplemented)
+	}
+
+	if code := do(t, h, "POST", "/seek", `{}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("empty seek: have %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		code int
+	}{
+		{&vlcapi.VLCError{What: "Media is not seekable"}, http.StatusConflict},
+		{badRequest("Invalid track type %q", "x"), http.StatusBadRequest},
+		{errNoPlaylist, http.StatusNotImplemented},
+		{errors.New("VLC is unable to open the MRL"), http.StatusInternalServerError},
+	} {
+		w := httptest.NewRecorder()
+		writeError(w, tt.err)
+
+		if w.Code != tt.code {
+			t.Errorf("%v: have %d, want %d", tt.err, w.Code, tt.code)
+		}
+
+		var v map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil || v["error"] != tt.err.Error() {
+			t.Errorf("%v: have body %q", tt.err, w.Body)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package sout
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Transcodes audio, video and subtitle streams. Zero fields are omitted, so
+// the corresponding streams are passed through unchanged.
+type Transcode struct {
+	VCodec      string  // Video codec fourcc, e.g. "h264".
+	VBitrate    int     // Video bitrate in kbit/s.
+	Scale       float64 // Video scale factor.
+	FPS         float64 // Output frame rate.
+	Width       int     // Output width in pixels.
+	Height      int     // Output height in pixels.
+	MaxWidth    int     // Maximum output width in pixels.
+	MaxHeight   int     // Maximum output height in pixels.
+	Deinterlace bool    // Deinterlace the video.
+	VFilter     string  // Video filter chain.
+	ACodec      string  // Audio codec fourcc, e.g. "mp4a".
+	ABitrate    int     // Audio bitrate in kbit/s.
+	Channels    int     // Number of audio channels.
+	SampleRate  int     // Audio sample rate in Hz.
+	AFilter     string  // Audio filter chain.
+	SCodec      string  // Subtitle codec fourcc.
+	SOverlay    bool    // Burn subtitles into the video.
+	Threads     int     // Number of encoder threads.
+	Extra       []Option
+}
+
+func (this *Transcode) Name() string { return "transcode" }
+
+func (this *Transcode) Options() []Option {
+	var o options
+	o.str("vcodec", this.VCodec)
+	o.int("vb", this.VBitrate)
+	o.float("scale", this.Scale)
+	o.float("fps", this.FPS)
+	o.int("width", this.Width)
+	o.int("height", this.Height)
+	o.int("maxwidth", this.MaxWidth)
+	o.int("maxheight", this.MaxHeight)
+	o.flag("deinterlace", this.Deinterlace)
+	o.str("vfilter", this.VFilter)
+	o.str("acodec", this.ACodec)
+	o.int("ab", this.ABitrate)
+	o.int("channels", this.Channels)
+	o.int("samplerate", this.SampleRate)
+	o.str("afilter", this.AFilter)
+	o.str("scodec", this.SCodec)
+	o.flag("soverlay", this.SOverlay)
+	o.int("threads", this.Threads)
+	return append(o, this.Extra...)
+}
+
+func (this *Transcode) set(opts []Option) (err error) {
+	for _, v := range opts {
+		switch v.Key {
+		case "vcodec":
+			this.VCodec = v.Value
+		case "vb":
+			this.VBitrate, err = parseInt(v)
+		case "scale":
+			this.Scale, err = parseFloat(v)
+		case "fps":
+			this.FPS, err = parseFloat(v)
+		case "width":
+			this.Width, err = parseInt(v)
+		case "height":
+			this.Height, err = parseInt(v)
+		case "maxwidth":
+			this.MaxWidth, err = parseInt(v)
+		case "maxheight":
+			this.MaxHeight, err = parseInt(v)
+		case "deinterlace":
+			this.Deinterlace, err = parseFlag(v)
+		case "vfilter":
+			this.VFilter = v.Value
+		case "acodec":
+			this.ACodec = v.Value
+		case "ab":
+			this.ABitrate, err = parseInt(v)
+		case "channels":
+			this.Channels, err = parseInt(v)
+		case "samplerate":
+			this.SampleRate, err = parseInt(v)
+		case "afilter":
+			this.AFilter = v.Value
+		case "scodec":
+			this.SCodec = v.Value
+		case "soverlay":
+			this.SOverlay, err = parseFlag(v)
+		case "threads":
+			this.Threads, err = parseInt(v)
+		default:
+			this.Extra = append(this.Extra, v)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Writes the stream to a file or network destination.
+type Std struct {
+	Access string // Access output, e.g. "file", "http" or "udp".
+	Mux    string // Muxer, e.g. "ts", "mp4" or "ogg".
+	Dst    string // Destination path or address.
+	Extra  []Option
+}
+
+func (this *Std) Name() string { return "std" }
+
+func (this *Std) Options() []Option {
+	var o options
+	o.str("access", this.Access)
+	o.str("mux", this.Mux)
+	o.str("dst", this.Dst)
+	return append(o, this.Extra...)
+}
+
+func (this *Std) set(opts []Option) error {
+	for _, v := range opts {
+		switch v.Key {
+		case "access":
+			this.Access = v.Value
+		case "mux":
+			this.Mux = v.Value
+		case "dst":
+			this.Dst = v.Value
+		default:
+			this.Extra = append(this.Extra, v)
+		}
+	}
+
+	return nil
+}
+
+// Like Std, but with separate settings for audio and video streams. Empty
+// per-stream settings fall back to the common ones.
+type Es struct {
+	Access      string
+	AccessAudio string
+	AccessVideo string
+	Mux         string
+	MuxAudio    string
+	MuxVideo    string
+	Dst         string
+	DstAudio    string
+	DstVideo    string
+	Extra       []Option
+}
+
+func (this *Es) Name() string { return "es" }
+
+func (this *Es) Options() []Option {
+	var o options
+	o.str("access", this.Access)
+	o.str("access-audio", this.AccessAudio)
+	o.str("access-video", this.AccessVideo)
+	o.str("mux", this.Mux)
+	o.str("mux-audio", this.MuxAudio)
+	o.str("mux-video", this.MuxVideo)
+	o.str("dst", this.Dst)
+	o.str("dst-audio", this.DstAudio)
+	o.str("dst-video", this.DstVideo)
+	return append(o, this.Extra...)
+}
+
+func (this *Es) set(opts []Option) error {
+	for _, v := range opts {
+		switch v.Key {
+		case "access":
+			this.Access = v.Value
+		case "access-audio":
+			this.AccessAudio = v.Value
+		case "access-video":
+			this.AccessVideo = v.Value
+		case "mux":
+			this.Mux = v.Value
+		case "mux-audio":
+			this.MuxAudio = v.Value
+		case "mux-video":
+			this.MuxVideo = v.Value
+		case "dst":
+			this.Dst = v.Value
+		case "dst-audio":
+			this.DstAudio = v.Value
+		case "dst-video":
+			this.DstVideo = v.Value
+		default:
+			this.Extra = append(this.Extra, v)
+		}
+	}
+
+	return nil
+}
+
+// Plays the stream locally while it is being output.
+type Display struct {
+	NoAudio bool // Do not play audio.
+	NoVideo bool // Do not show video.
+	Delay   int  // Delay in milliseconds.
+	Extra   []Option
+}
+
+func (this *Display) Name() string { return "display" }
+
+func (this *Display) Options() []Option {
+	var o options
+	o.flag("noaudio", this.NoAudio)
+	o.flag("novideo", this.NoVideo)
+	o.int("delay", this.Delay)
+	return append(o, this.Extra...)
+}
+
+func (this *Display) set(opts []Option) (err error) {
+	for _, v := range opts {
+		var b bool
+
+		switch v.Key {
+		case "noaudio", "no-audio":
+			this.NoAudio, err = parseFlag(v)
+		case "novideo", "no-video":
+			this.NoVideo, err = parseFlag(v)
+		case "audio":
+			b, err = parseFlag(v)
+			this.NoAudio = !b
+		case "video":
+			b, err = parseFlag(v)
+			this.NoVideo = !b
+		case "delay":
+			this.Delay, err = parseInt(v)
+		default:
+			this.Extra = append(this.Extra, v)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Collects stream information without outputting anything. Used to inspect
+// a media's elementary streams.
+type Description struct {
+	Extra []Option
+}
+
+func (this *Description) Name() string { return "description" }
+
+func (this *Description) Options() []Option { return this.Extra }
+
+func (this *Description) set(opts []Option) error {
+	this.Extra = opts
+	return nil
+}
+
+// Sends the stream to multiple destinations.
+type Duplicate struct {
+	Dst   []Destination
+	Extra []Option
+}
+
+// A single Duplicate destination.
+type Destination struct {
+	Chain  Chain  // The chain receiving the stream.
+	Select string // Optional stream filter, e.g. "es=1" or "noaudio".
+}
+
+func (this *Duplicate) Name() string { return "duplicate" }
+
+func (this *Duplicate) Options() []Option {
+	var o options
+	for _, d := range this.Dst {
+		o = append(o, Option{Key: "dst", Chain: d.Chain})
+		o.str("select", d.Select)
+	}
+	return append(o, this.Extra...)
+}
+
+func (this *Duplicate) set(opts []Option) error {
+	for _, v := range opts {
+		switch {
+		case v.Key == "dst":
+			this.Dst = append(this.Dst, Destination{Chain: v.Chain})
+		case v.Key == "select" && len(this.Dst) > 0:
+			this.Dst[len(this.Dst)-1].Select = v.Value
+		default:
+			this.Extra = append(this.Extra, v)
+		}
+	}
+
+	return nil
+}
+
+// Any module without a typed counterpart in this package.
+type Raw struct {
+	Module string
+	Opts   []Option
+}
+
+func (this *Raw) Name() string      { return this.Module }
+func (this *Raw) Options() []Option { return this.Opts }
+
+// newModule returns the typed module for name, initialized from opts.
+func newModule(name string, opts []Option) (Module, error) {
+	var m interface {
+		Module
+		set([]Option) error
+	}
+
+	switch name {
+	case "transcode":
+		m = new(Transcode)
+	case "std":
+		m = new(Std)
+	case "es":
+		m = new(Es)
+	case "display":
+		m = new(Display)
+	case "description":
+		m = new(Description)
+	case "duplicate":
+		m = new(Duplicate)
+	default:
+		return &Raw{name, opts}, nil
+	}
+
+	if err := m.set(opts); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// isChainOption returns true if the given module option holds a nested
+// chain.
+func isChainOption(module, key string) bool {
+	return module == "duplicate" && key == "dst"
+}
+
+// Helpers to append options which are not zero.
+type options []Option
+
+func (o *options) str(k, v string) {
+	if v != "" {
+		*o = append(*o, Option{Key: k, Value: v})
+	}
+}
+
+func (o *options) int(k string, v int) {
+	if v != 0 {
+		*o = append(*o, Option{Key: k, Value: strconv.Itoa(v)})
+	}
+}
+
+func (o *options) float(k string, v float64) {
+	if v != 0 {
+		*o = append(*o, Option{Key: k, Value: strconv.FormatFloat(v, 'g', -1, 64)})
+	}
+}
+
+func (o *options) flag(k string, v bool) {
+	if v {
+		*o = append(*o, Option{Key: k})
+	}
+}
+
+func parseInt(o Option) (int, error) {
+	v, err := strconv.Atoi(o.Value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q for %s", o.Value, o.Key)
+	}
+	return v, nil
+}
+
+func parseFloat(o Option) (float64, error) {
+	v, err := strconv.ParseFloat(o.Value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q for %s", o.Value, o.Key)
+	}
+	return v, nil
+}
+
+// parseFlag parses a boolean option. A flag without a value is true.
+func parseFlag(o Option) (bool, error) {
+	switch o.Value {
+	case "", "1", "true", "yes":
+		return true, nil
+	case "0", "false", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid flag %q for %s", o.Value, o.Key)
+}
@@ -0,0 +1,310 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+// Package sout builds and parses libvlc stream output chains, such as
+//
+//	#transcode{vcodec=h264,vb=800}:std{access=file,mux=ts,dst=out.ts}
+//
+// A Chain is a list of modules, each of which renders its own options.
+// Values are quoted and escaped as needed when a chain is rendered, so they
+// may contain any character.
+package sout
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A single module in a stream output chain.
+type Module interface {
+	// Name returns the module name, e.g. "transcode".
+	Name() string
+
+	// Options returns the module options in the order they are rendered.
+	Options() []Option
+}
+
+// A module option. An option with neither Value nor Chain set is a flag
+// and is rendered as its key alone, unless Empty is set.
+type Option struct {
+	Key   string
+	Value string
+	Chain Chain // Nested chain, e.g. a duplicate destination. Overrides Value.
+	Empty bool  // Render an empty Value as key="" rather than as a flag.
+}
+
+// Renders the option as key=value.
+func (this Option) String() string {
+	switch {
+	case this.Chain != nil:
+		return this.Key + "=" + this.Chain.render()
+	case this.Value == "" && !this.Empty:
+		return this.Key
+	}
+
+	return this.Key + "=" + quote(this.Value)
+}
+
+// A stream output chain. Modules process the stream in order.
+type Chain []Module
+
+// New creates a chain from the given modules.
+func New(modules ...Module) Chain { return Chain(modules) }
+
+// Renders the chain in the form accepted by the sout option, including the
+// leading '#'.
+func (this Chain) String() string {
+	if len(this) == 0 {
+		return ""
+	}
+
+	return "#" + this.render()
+}
+
+// render renders the chain without the leading '#', as used for nested
+// chains.
+func (this Chain) render() string {
+	list := make([]string, len(this))
+
+	for i, m := range this {
+		list[i] = renderModule(m)
+	}
+
+	return strings.Join(list, ":")
+}
+
+func renderModule(m Module) string {
+	opts := m.Options()
+	if len(opts) == 0 {
+		return m.Name()
+	}
+
+	list := make([]string, len(opts))
+	for i, o := range opts {
+		list[i] = o.String()
+	}
+
+	return m.Name() + "{" + strings.Join(list, ",") + "}"
+}
+
+// quote returns v quoted and escaped if it contains characters that are
+// special to the chain syntax.
+func quote(v string) string {
+	if v != "" && !strings.ContainsAny(v, "{}:,=\"'\\ \t\n") {
+		return v
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+
+	for i := 0; i < len(v); i++ {
+		if v[i] == '"' || v[i] == '\'' || v[i] == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(v[i])
+	}
+
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// Parse parses a stream output chain. The leading '#' is optional. Known
+// modules are returned as their typed counterparts, e.g. *Transcode; other
+// modules are returned as *Raw.
+func Parse(s string) (Chain, error) {
+	p := &parser{s: strings.TrimSpace(s)}
+
+	if strings.HasPrefix(p.s, "#") {
+		p.pos++
+	}
+
+	c, err := p.chain()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+
+	return c, nil
+}
+
+// Error returned by Parse() for malformed chains.
+type ParseError struct {
+	Input  string
+	Offset int
+	What   string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("sout: %s at offset %d in %q", e.What, e.Offset, e.Input)
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) eof() bool  { return p.pos >= len(p.s) }
+func (p *parser) peek() byte { return p.s[p.pos] }
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &ParseError{p.s, p.pos, fmt.Sprintf(format, args...)}
+}
+
+// chain parses modules separated by ':', up to the end of input or a
+// character which ends an enclosing option value.
+func (p *parser) chain() (Chain, error) {
+	var c Chain
+
+	for {
+		m, err := p.module()
+		if err != nil {
+			return nil, err
+		}
+
+		c = append(c, m)
+
+		if p.eof() || p.peek() != ':' {
+			return c, nil
+		}
+
+		p.pos++
+	}
+}
+
+func (p *parser) module() (Module, error) {
+	start := p.pos
+	for !p.eof() && strings.IndexByte("{}:,=\"'", p.peek()) < 0 {
+		p.pos++
+	}
+
+	name := strings.TrimSpace(p.s[start:p.pos])
+	if name == "" {
+		return nil, p.errorf("missing module name")
+	}
+
+	var opts []Option
+
+	if !p.eof() && p.peek() == '{' {
+		p.pos++
+
+		var err error
+		if opts, err = p.options(name); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := newModule(name, opts)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+
+	return m, nil
+}
+
+// options parses a module's options, including the closing '}'.
+func (p *parser) options(module string) ([]Option, error) {
+	var opts []Option
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorf("missing '}'")
+		}
+
+		if p.peek() == '}' {
+			p.pos++
+			return opts, nil
+		}
+
+		start := p.pos
+		for !p.eof() && strings.IndexByte("{}:,=\"'", p.peek()) < 0 {
+			p.pos++
+		}
+
+		o := Option{Key: strings.TrimSpace(p.s[start:p.pos])}
+		if o.Key == "" {
+			return nil, p.errorf("missing option name")
+		}
+
+		if !p.eof() && p.peek() == '=' {
+			p.pos++
+			p.skipSpace()
+
+			var err error
+			if isChainOption(module, o.Key) {
+				o.Chain, err = p.chain()
+			} else {
+				o.Value, err = p.value()
+				o.Empty = o.Value == ""
+			}
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		opts = append(opts, o)
+
+		p.skipSpace()
+		if !p.eof() && p.peek() == ',' {
+			p.pos++
+		}
+	}
+}
+
+// value parses an option value, which is either quoted or runs up to the
+// next ',' or '}' outside of braces.
+func (p *parser) value() (string, error) {
+	if p.eof() {
+		return "", p.errorf("missing value")
+	}
+
+	if q := p.peek(); q == '"' || q == '\'' {
+		p.pos++
+
+		var sb strings.Builder
+		for !p.eof() && p.peek() != q {
+			if p.peek() == '\\' && p.pos+1 < len(p.s) {
+				p.pos++
+			}
+			sb.WriteByte(p.peek())
+			p.pos++
+		}
+
+		if p.eof() {
+			return "", p.errorf("missing closing %c", q)
+		}
+
+		p.pos++
+		return sb.String(), nil
+	}
+
+	start, depth := p.pos, 0
+	for ; !p.eof(); p.pos++ {
+		switch p.peek() {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return strings.TrimSpace(p.s[start:p.pos]), nil
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				return strings.TrimSpace(p.s[start:p.pos]), nil
+			}
+		}
+	}
+
+	return strings.TrimSpace(p.s[start:p.pos]), nil
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) >= 0 {
+		p.pos++
+	}
+}
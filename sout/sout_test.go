@@ -0,0 +1,83 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package sout
+
+import (
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	c := New(
+		&Transcode{VCodec: "h264", VBitrate: 800, Scale: 0.5, ACodec: "mp4a", SOverlay: true},
+		&Duplicate{Dst: []Destination{
+			{Chain: New(&Display{NoAudio: true})},
+			{Chain: New(&Std{Access: "file", Mux: "ts", Dst: "/tmp/my out,1.ts"}), Select: "es=1"},
+		}},
+	)
+
+	want := `#transcode{vcodec=h264,vb=800,scale=0.5,acodec=mp4a,soverlay}:` +
+		`duplicate{dst=display{noaudio},dst=std{access=file,mux=ts,dst="/tmp/my out,1.ts"},select="es=1"}`
+
+	if got := c.String(); got != want {
+		t.Fatalf("String:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{
+		`#transcode{vcodec=h264,vb=800,scale=0.5,acodec=mp4a,soverlay}:std{access=file,mux=ts,dst=out.ts}`,
+		`#duplicate{dst=display,dst=transcode{vcodec=theo}:std{access=http,mux=ogg,dst=":8080/a b.ogg"},select="es=1"}`,
+		`#es{access=file,mux-audio=ogg,dst-video="v \"1\".ts"}:description`,
+		`#rtp{sdp=rtsp://:5544/,mux=ts}`,
+	} {
+		c, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", s, err)
+		}
+
+		// Rendering may quote differently, but must parse to the same chain.
+		r, err := Parse(c.String())
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", c.String(), err)
+		}
+
+		if r.String() != c.String() {
+			t.Fatalf("Round trip:\n got %s\nwant %s", r.String(), c.String())
+		}
+	}
+
+	c, err := Parse(`#std{access=file,dst="a \"b\", c"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if std, ok := c[0].(*Std); !ok || std.Dst != `a "b", c` {
+		t.Fatalf("Parse: unexpected module %#v", c[0])
+	}
+
+	for _, s := range []string{`#std{access=file`, `#std{dst="x}`, `#{}`, `#transcode{vb=fast}`} {
+		if _, err := Parse(s); err == nil {
+			t.Fatalf("Parse(%s): expected error", s)
+		}
+	}
+}
+
+func TestEmptyValue(t *testing.T) {
+	r := &Raw{Module: "custom", Opts: []Option{{Key: "access", Value: "file"}, {Key: "dst", Empty: true}, {Key: "flag"}}}
+
+	want := `#custom{access=file,dst="",flag}`
+	if got := New(r).String(); got != want {
+		t.Fatalf("String:\n got %s\nwant %s", got, want)
+	}
+
+	c, err := Parse(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.String(); got != want {
+		t.Fatalf("Round trip:\n got %s\nwant %s", got, want)
+	}
+}
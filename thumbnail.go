@@ -0,0 +1,275 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"time"
+)
+
+// Options for Media.Thumbnail().
+type ThumbnailOptions struct {
+	// Where to take the thumbnail. Time takes precedence; if it is zero,
+	// Position is used as a fraction of the media duration, between 0.0
+	// and 1.0.
+	Time     time.Duration
+	Position float32
+
+	// Size of the thumbnail. If both are zero, the source size is used. If
+	// one is zero, it is derived from the other, keeping the aspect ratio.
+	Width  uint
+	Height uint
+
+	// If both Width and Height are set and Crop is false, the picture is
+	// scaled to fit within them, keeping its aspect ratio. If Crop is true,
+	// it is scaled to cover them and cropped to exactly that size.
+	Crop bool
+
+	// How to seek to the thumbnail position. SeekFast takes the thumbnail
+	// from the nearest key frame, which is quicker but less accurate.
+	Seek SeekMode
+}
+
+// Thumbnail decodes a single video frame at the position given in opts and
+// returns it as an image. No video output or window is needed.
+//
+// The media itself is left untouched; decoding happens on a duplicate with
+// its own player. Thumbnail blocks until a frame was decoded, playback fails
+// or ctx is done.
+func (this *Media) Thumbnail(ctx context.Context, opts ThumbnailOptions) (image.Image, error) {
+	if this.ptr == nil {
+		return nil, &VLCError{"Media is nil"}
+	}
+
+	m, err := this.Duplicate()
+	if err != nil {
+		return nil, err
+	}
+
+	defer m.Release()
+
+	at := opts.Time
+	if at <= 0 && opts.Position > 0 {
+		s, err := m.ParseContext(ctx, ParseOptions{Flags: ParseNetwork})
+		if err != nil {
+			return nil, err
+		}
+
+		if s != PSDone {
+			return nil, &VLCError{"Failed to parse media"}
+		}
+
+		d := m.Duration()
+		if d <= 0 {
+			return nil, &VLCError{"Media duration is unknown"}
+		}
+
+		at = time.Duration(float64(opts.Position) * float64(d) * float64(time.Millisecond))
+	}
+
+	options := []string{":no-audio", ":no-spu", fmt.Sprintf(":start-time=%.3f", at.Seconds())}
+	if opts.Seek == SeekFast {
+		options = append(options, ":input-fast-seek")
+	}
+
+	for _, o := range options {
+		if err := m.AddOption(o); err != nil {
+			return nil, err
+		}
+	}
+
+	src, err := m.grabFrame(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Correct for non-square pixels, so the aspect ratio comes out right.
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	if tracks, _ := m.Tracks(); tracks != nil {
+		for _, t := range tracks {
+			if v, ok := t.(*VideoTrack); ok && v.SARNum > 0 && v.SARDen > 0 {
+				sw = sw * int(v.SARNum) / int(v.SARDen)
+				break
+			}
+		}
+	}
+
+	w, h, crop := thumbnailSize(sw, sh, int(opts.Width), int(opts.Height), opts.Crop)
+	if w == b.Dx() && h == b.Dy() && crop == b {
+		return src, nil
+	}
+
+	return scaleRGBA(src, crop, float64(b.Dx())/float64(sw), w, h), nil
+}
+
+// EncodeThumbnail is like Media.Thumbnail(), but returns the image encoded in
+// the given format.
+func (this *Media) EncodeThumbnail(ctx context.Context, opts ThumbnailOptions, format ImageFormat) ([]byte, error) {
+	img, err := this.Thumbnail(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	switch format {
+	case IFPNG:
+		err = png.Encode(&buf, img)
+	case IFJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
+	default:
+		err = &VLCError{fmt.Sprintf("Unsupported image format: %d", format)}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// grabFrame plays the media until its first frame is displayed.
+func (this *Media) grabFrame(ctx context.Context) (*image.RGBA, error) {
+	p, err := this.NewPlayer()
+	if err != nil {
+		return nil, err
+	}
+
+	defer p.Release()
+
+	sink, err := p.FrameSink(1)
+	if err != nil {
+		return nil, err
+	}
+
+	defer sink.Close()
+
+	em, err := p.Events()
+	if err != nil {
+		return nil, err
+	}
+
+	events, cancel, err := em.Subscribe(MediaPlayerEncounteredError, MediaPlayerEndReached)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cancel()
+
+	if err = p.Play(); err != nil {
+		return nil, err
+	}
+
+	// The sink must only be closed once playback has stopped.
+	defer p.Stop()
+
+	select {
+	case f := <-sink.C:
+		return f.Image.(*image.RGBA), nil
+
+	case evt := <-events:
+		if evt.Type == MediaPlayerEncounteredError {
+			return nil, &VLCError{"Failed to decode media"}
+		}
+		return nil, &VLCError{"No video frame at the requested position"}
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// thumbnailSize computes the size of a thumbnail of a source with the given
+// display size, along with the source rectangle it is scaled from.
+func thumbnailSize(sw, sh, w, h int, crop bool) (int, int, image.Rectangle) {
+	src := image.Rect(0, 0, sw, sh)
+
+	switch {
+	case sw == 0 || sh == 0:
+		return sw, sh, src
+
+	case w == 0 && h == 0:
+		return sw, sh, src
+
+	case w == 0:
+		return max(1, sw*h/sh), h, src
+
+	case h == 0:
+		return w, max(1, sh*w/sw), src
+
+	case !crop:
+		if sw*h > sh*w {
+			return w, max(1, sh*w/sw), src
+		}
+		return max(1, sw*h/sh), h, src
+	}
+
+	// Crop the source to the aspect ratio of the thumbnail, centered.
+	if sw*h > sh*w {
+		cw := sh * w / h
+		x := (sw - cw) / 2
+		src = image.Rect(x, 0, x+cw, sh)
+	} else {
+		ch := sw * h / w
+		y := (sh - ch) / 2
+		src = image.Rect(0, y, sw, y+ch)
+	}
+
+	return w, h, src
+}
+
+// scaleRGBA scales the rectangle r of src to a new w by h image, averaging
+// all source pixels covered by each destination pixel. The rectangle is in
+// display coordinates, which are sx times narrower than the storage
+// coordinates of src when the source has non-square pixels.
+func scaleRGBA(src *image.RGBA, r image.Rectangle, sx float64, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	b := src.Bounds()
+
+	for y := 0; y < h; y++ {
+		y0, y1 := boxSpan(float64(r.Min.Y), float64(r.Dy())/float64(h), y, b.Dy())
+
+		for x := 0; x < w; x++ {
+			x0, x1 := boxSpan(float64(r.Min.X)*sx, float64(r.Dx())*sx/float64(w), x, b.Dx())
+
+			var sum [4]int
+			for py := y0; py < y1; py++ {
+				for px := x0; px < x1; px++ {
+					p := src.PixOffset(px, py)
+					for c := range sum {
+						sum[c] += int(src.Pix[p+c])
+					}
+				}
+			}
+
+			n := (x1 - x0) * (y1 - y0)
+			d := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[d+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+
+	return dst
+}
+
+// boxSpan returns the range of source pixels covered by destination pixel i,
+// given the source offset and the source size of a destination pixel. The
+// range always holds at least one pixel and lies within [0, n).
+func boxSpan(offset, scale float64, i, n int) (int, int) {
+	lo := int(offset + float64(i)*scale)
+	hi := int(math.Ceil(offset + float64(i+1)*scale))
+
+	lo = min(max(lo, 0), n-1)
+	hi = min(max(hi, lo+1), n)
+	return lo, hi
+}
@@ -0,0 +1,83 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbnailSize(t *testing.T) {
+	for _, tt := range []struct {
+		sw, sh, w, h int
+		crop         bool
+		ww, wh       int
+		src          image.Rectangle
+	}{
+		{640, 480, 0, 0, false, 640, 480, image.Rect(0, 0, 640, 480)},
+		{640, 480, 320, 0, false, 320, 240, image.Rect(0, 0, 640, 480)},
+		{640, 480, 0, 120, false, 160, 120, image.Rect(0, 0, 640, 480)},
+		{640, 480, 100, 100, false, 100, 75, image.Rect(0, 0, 640, 480)},
+		{480, 640, 100, 100, false, 75, 100, image.Rect(0, 0, 480, 640)},
+		{640, 480, 100, 100, true, 100, 100, image.Rect(80, 0, 560, 480)},
+		{480, 640, 100, 50, true, 100, 50, image.Rect(0, 200, 480, 440)},
+		{1000, 10, 0, 1, false, 100, 1, image.Rect(0, 0, 1000, 10)},
+		{1000, 10, 1, 0, false, 1, 1, image.Rect(0, 0, 1000, 10)},
+		{0, 0, 100, 100, false, 0, 0, image.Rect(0, 0, 0, 0)},
+	} {
+		w, h, src := thumbnailSize(tt.sw, tt.sh, tt.w, tt.h, tt.crop)
+		if w != tt.ww || h != tt.wh || src != tt.src {
+			t.Errorf("thumbnailSize(%d, %d, %d, %d, %v): have %dx%d %v, want %dx%d %v",
+				tt.sw, tt.sh, tt.w, tt.h, tt.crop, w, h, src, tt.ww, tt.wh, tt.src)
+		}
+	}
+}
+
+func TestBoxSpan(t *testing.T) {
+	for _, tt := range []struct {
+		offset, scale float64
+		i, n          int
+		lo, hi        int
+	}{
+		{0, 2, 1, 10, 2, 4},
+		{0, 0.5, 3, 10, 1, 2},
+		{1.5, 1.5, 0, 10, 1, 3},
+		{0, 2, 9, 10, 9, 10},
+		{5, 0.1, 0, 4, 3, 4},
+	} {
+		lo, hi := boxSpan(tt.offset, tt.scale, tt.i, tt.n)
+		if lo != tt.lo || hi != tt.hi {
+			t.Errorf("boxSpan(%v, %v, %d, %d): have [%d, %d), want [%d, %d)",
+				tt.offset, tt.scale, tt.i, tt.n, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestScaleRGBA(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			src.SetRGBA(x, y, color.RGBA{uint8(x * 10), uint8(y * 100), 0, 255})
+		}
+	}
+
+	// Each destination pixel averages a 2x2 block.
+	dst := scaleRGBA(src, src.Bounds(), 1, 2, 1)
+	for x, want := range []color.RGBA{{5, 50, 0, 255}, {25, 50, 0, 255}} {
+		if have := dst.RGBAAt(x, 0); have != want {
+			t.Fatalf("pixel %d: have %v, want %v", x, have, want)
+		}
+	}
+
+	// Pixels twice as wide as they are high: a 4x2 display rectangle maps
+	// onto 2x2 storage pixels, each of which is repeated.
+	dst = scaleRGBA(src, image.Rect(0, 0, 4, 2), 0.5, 4, 2)
+	for x, sx := range []int{0, 0, 1, 1} {
+		if have, want := dst.RGBAAt(x, 1), src.RGBAAt(sx, 1); have != want {
+			t.Fatalf("pixel %d: have %v, want %v", x, have, want)
+		}
+	}
+}
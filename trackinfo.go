@@ -5,14 +5,135 @@
 package vlc
 
 // #include "glue.h"
+//
+// static libvlc_audio_track_t* goTrackAudio(libvlc_media_track_t* t) { return t->audio; }
+// static libvlc_video_track_t* goTrackVideo(libvlc_media_track_t* t) { return t->video; }
+// static libvlc_subtitle_track_t* goTrackSubtitle(libvlc_media_track_t* t) { return t->subtitle; }
 import "C"
 import (
 	"bytes"
 	"encoding/binary"
 )
 
+// A single elementary stream of a media, as returned by Media.Tracks().
+// Use a type switch to get at the *AudioTrack, *VideoTrack or *SubtitleTrack.
+type MediaTrack interface {
+	// Info returns the properties common to all tracks.
+	Info() *Track
+}
+
+// Properties common to all media tracks.
+type Track struct {
+	Type          TrackType
+	Codec         uint32 // Codec fourcc.
+	OriginalCodec uint32 // Fourcc of the stream as stored in the container.
+	Id            int
+	Profile       int
+	Level         int
+	Bitrate       uint // Bits per second, if known.
+	Language      string
+	Description   string
+}
+
+func (this *Track) Info() *Track { return this }
+
+// CodecName returns the codec fourcc as a string, e.g. "h264".
+func (this *Track) CodecName() string { return fourcc(this.Codec) }
+
+// An audio track.
+type AudioTrack struct {
+	Track
+	Channels uint
+	Rate     uint // Sample rate in Hz.
+}
+
+// A video track.
+type VideoTrack struct {
+	Track
+	Width        uint
+	Height       uint
+	SARNum       uint // Sample aspect ratio numerator.
+	SARDen       uint // Sample aspect ratio denominator.
+	FrameRateNum uint
+	FrameRateDen uint
+	Orientation  VideoOrientation
+	Projection   VideoProjection
+}
+
+// FrameRate returns the frame rate in frames per second, or 0 if unknown.
+func (this *VideoTrack) FrameRate() float64 {
+	if this.FrameRateDen == 0 {
+		return 0
+	}
+	return float64(this.FrameRateNum) / float64(this.FrameRateDen)
+}
+
+// A subtitle track.
+type SubtitleTrack struct {
+	Track
+	Encoding string // Character encoding of text subtitles.
+}
+
+func newMediaTrack(c *C.libvlc_media_track_t) MediaTrack {
+	t := Track{
+		Type:          TrackType(c.i_type),
+		Codec:         uint32(c.i_codec),
+		OriginalCodec: uint32(c.i_original_fourcc),
+		Id:            int(c.i_id),
+		Profile:       int(c.i_profile),
+		Level:         int(c.i_level),
+		Bitrate:       uint(c.i_bitrate),
+	}
+
+	if c.psz_language != nil {
+		t.Language = C.GoString(c.psz_language)
+	}
+
+	if c.psz_description != nil {
+		t.Description = C.GoString(c.psz_description)
+	}
+
+	switch t.Type {
+	case TTAudio:
+		if a := C.goTrackAudio(c); a != nil {
+			return &AudioTrack{t, uint(a.i_channels), uint(a.i_rate)}
+		}
+
+	case TTVideo:
+		if v := C.goTrackVideo(c); v != nil {
+			return &VideoTrack{
+				Track:        t,
+				Width:        uint(v.i_width),
+				Height:       uint(v.i_height),
+				SARNum:       uint(v.i_sar_num),
+				SARDen:       uint(v.i_sar_den),
+				FrameRateNum: uint(v.i_frame_rate_num),
+				FrameRateDen: uint(v.i_frame_rate_den),
+				Orientation:  VideoOrientation(v.i_orientation),
+				Projection:   VideoProjection(v.i_projection),
+			}
+		}
+
+	case TTText:
+		st := &SubtitleTrack{Track: t}
+		if s := C.goTrackSubtitle(c); s != nil && s.psz_encoding != nil {
+			st.Encoding = C.GoString(s.psz_encoding)
+		}
+		return st
+	}
+
+	return &t
+}
+
+// fourcc returns v as a four-character code.
+func fourcc(v uint32) string {
+	return string([]byte{byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24)})
+}
+
 // Represents a single media track. Can be audio or video.
 // Access Audio() or Video() depending on the value of Type.
+//
+// Deprecated: Use MediaTrack, as returned by Media.Tracks().
 type TrackInfo struct {
 	ptr *C.libvlc_media_track_info_t
 }
@@ -0,0 +1,179 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jteeuwen/go-vlc/sout"
+)
+
+// Describes the output of Transcode().
+type TranscodeProfile struct {
+	Transcode sout.Transcode // Codec settings.
+	Mux       string         // Container format, e.g. "mp4", "ts" or "ogg".
+
+	// Progress, if set, is called with the position in the input, between
+	// 0.0 and 1.0, whenever it changes. It is called from the goroutine
+	// running Transcode().
+	Progress func(position float32)
+}
+
+// Common transcoding profiles.
+var (
+	// H.264 video and AAC audio in an MP4 container.
+	ProfileMP4 = TranscodeProfile{
+		Transcode: sout.Transcode{VCodec: "h264", VBitrate: 2000, ACodec: "mp4a", ABitrate: 128},
+		Mux:       "mp4",
+	}
+
+	// H.264 video and AAC audio in an MPEG transport stream.
+	ProfileTS = TranscodeProfile{
+		Transcode: sout.Transcode{VCodec: "h264", VBitrate: 2000, ACodec: "mp4a", ABitrate: 128},
+		Mux:       "ts",
+	}
+
+	// Theora video and Vorbis audio in an Ogg container.
+	ProfileOgg = TranscodeProfile{
+		Transcode: sout.Transcode{VCodec: "theo", VBitrate: 2000, ACodec: "vorb", ABitrate: 128},
+		Mux:       "ogg",
+	}
+
+	// MP3 audio only.
+	ProfileMP3 = TranscodeProfile{
+		Transcode: sout.Transcode{ACodec: "mp3", ABitrate: 192},
+		Mux:       "raw",
+	}
+)
+
+// Outcome of a successful Transcode().
+type TranscodeResult struct {
+	Output   string        // Path of the written file.
+	Size     int64         // Size of the written file in bytes.
+	Duration time.Duration // Duration of the input, if known.
+	Elapsed  time.Duration // Time spent transcoding.
+	Stats    *Stats        // Final input statistics, if available.
+}
+
+// Transcode converts the input to the output file according to the given
+// profile, and blocks until it is done. The input is a local path or a URI
+// such as "http://example.com/a.ogg".
+//
+// The media is processed as fast as possible, without being shown or heard.
+// If ctx is done before the input has been processed, transcoding stops and
+// ctx.Err() is returned; the output file is then incomplete.
+func Transcode(ctx context.Context, inst *Instance, input, output string, profile TranscodeProfile) (*TranscodeResult, error) {
+	if inst == nil || inst.ptr == nil {
+		return nil, &VLCError{"Instance is nil"}
+	}
+
+	if profile.Mux == "" {
+		return nil, &VLCError{"Transcode profile has no mux"}
+	}
+
+	var m *Media
+	var err error
+
+	if strings.Contains(input, "://") {
+		m, err = inst.OpenMediaUri(input)
+	} else {
+		m, err = inst.OpenMediaFile(input)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer m.Release()
+
+	tc := profile.Transcode
+	c := sout.New(&tc, &sout.Std{Access: "file", Mux: profile.Mux, Dst: output})
+
+	if err = m.SetStreamOutput(c); err != nil {
+		return nil, err
+	}
+
+	p, err := m.NewPlayer()
+	if err != nil {
+		return nil, err
+	}
+
+	defer p.Release()
+
+	em, err := p.Events()
+	if err != nil {
+		return nil, err
+	}
+
+	events, cancel, err := em.Subscribe(MediaPlayerPositionChanged,
+		MediaPlayerEndReached, MediaPlayerEncounteredError)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cancel()
+
+	start := time.Now()
+
+	if err = p.Play(); err != nil {
+		return nil, err
+	}
+
+	// Stopping flushes the muxer, so this must happen before the output
+	// is inspected.
+	stopped := false
+	stop := func() {
+		if !stopped {
+			p.Stop()
+			stopped = true
+		}
+	}
+
+	defer stop()
+
+	for done := false; !done; {
+		select {
+		case evt := <-events:
+			switch evt.Type {
+			case MediaPlayerPositionChanged:
+				if pc, ok := evt.Payload().(*PositionChangedEvent); ok && profile.Progress != nil {
+					profile.Progress(pc.Position)
+				}
+
+			case MediaPlayerEndReached:
+				done = true
+
+			case MediaPlayerEncounteredError:
+				return nil, &VLCError{"Failed to transcode " + input}
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	r := &TranscodeResult{
+		Output:  output,
+		Elapsed: time.Since(start),
+	}
+
+	if d := m.Duration(); d > 0 {
+		r.Duration = time.Duration(d) * time.Millisecond
+	}
+
+	r.Stats, _ = m.Stats()
+	stop()
+
+	fi, err := os.Stat(output)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Size = fi.Size()
+	return r, nil
+}
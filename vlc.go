@@ -2,7 +2,7 @@
 // license. Its contents can be found at:
 // http://creativecommons.org/publicdomain/zero/1.0
 
-// Go bindings for libVLC 1.1.9.
+// Go bindings for libVLC 3.0.
 package vlc
 
 // #cgo        LDFLAGS: -lvlc
@@ -19,9 +19,9 @@ import (
 
 // libVLC version numbers.
 const (
-	VersionMajor    = 1
-	VersionMinor    = 1
-	VersionRevision = 9
+	VersionMajor    = 3
+	VersionMinor    = 0
+	VersionRevision = 0
 	VersionExtra    = 0
 
 	// Version as a single integer. Practical for version comparison.
@@ -31,10 +31,6 @@ const (
 // Version returns the libVLC version as a human-readable string.
 func VersionString() string { return C.GoString(C.libvlc_get_version()) }
 
-func (this EventType) String() string {
-	return C.GoString(C.libvlc_event_type_name(C.libvlc_event_type_t(this)))
-}
-
 // Clears the LibVLC error status for the current thread. This is optional.
 // By default, the error status is automatically overriden when a new error
 // occurs, and destroyed when the thread exits.
@@ -23,6 +23,9 @@ func Test(t *testing.T) {
 
 	defer inst.Release()
 
+	inst.SetLogger(WriterLogger(os.Stderr), nil)
+	defer inst.SetLogger(nil, nil)
+
 	inst.SetLogVerbosity(3)
 	fmt.Printf("Log level: %d\n", inst.LogVerbosity())
 
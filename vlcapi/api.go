@@ -0,0 +1,143 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+// Package vlcapi holds the interfaces, constants and event types shared by
+// package vlc and its fakes. Unlike package vlc, it does not use cgo, so code
+// written against it can be built and tested without libvlc.
+//
+// Package vlc defines aliases for everything in here, so users of libvlc
+// need not import this package. Packages which only need the interfaces,
+// such as vlctest and remote, import it instead of vlc.
+package vlcapi
+
+import (
+	"context"
+	"time"
+)
+
+// Playback related methods of a Media.
+type MediaAPI interface {
+	Mrl() string
+	Meta(mp MetaProperty) string
+	SetMeta(mp MetaProperty, v string)
+	AddOption(options string) error
+	Options() []string
+	Duration() int64
+	Length() (time.Duration, error)
+	State() MediaState
+	ParsedStatus() ParsedStatus
+	Subscribe(types ...EventType) (<-chan *Event, func(), error)
+	Release() error
+	Close() error
+}
+
+// Playback related methods of a Player.
+type PlayerAPI interface {
+	Media() (MediaAPI, error)
+	SetMedia(m MediaAPI) error
+	Play() error
+	Pause() error
+	TogglePause(pause bool) error
+	Stop() error
+	IsPlaying() bool
+	State() (MediaState, error)
+	Length() (int64, error)
+	Time() (int64, error)
+	SetTime(v int64)
+	Position() (float32, error)
+	SetPosition(v float32)
+	Elapsed() (time.Duration, error)
+	MediaLength() (time.Duration, error)
+	Seek(d time.Duration, mode SeekMode) error
+	SeekBy(d time.Duration, mode SeekMode) error
+	SeekPosition(pos float32, mode SeekMode) error
+	Rate() (float32, error)
+	SetRate(v float32) error
+	CanSeek() (bool, error)
+	CanPause() (bool, error)
+	Volume() (int, error)
+	SetVolume(v int) error
+	IsMute() (bool, error)
+	SetMute(toggle bool) error
+	WaitForState(ctx context.Context, states ...MediaState) (MediaState, error)
+	PlayAndWait(ctx context.Context) error
+	Subscribe(types ...EventType) (<-chan *Event, func(), error)
+	Release() error
+	Close() error
+}
+
+// Track selection methods of a Player. Players from package vlc implement
+// it; fakes need not.
+type TrackAPI interface {
+	AudioTracks() ([]Track, error)
+	AudioTrack() (int, error)
+	SetAudioTrack(track int) error
+	VideoTracks() ([]Track, error)
+	VideoTrack() (int, error)
+	SetVideoTrack(track int) error
+	SubtitleTracks() ([]Track, error)
+	SubTile() (int, error)
+	SetSubtitle(s int) error
+}
+
+// An audio, video or subtitle track, as listed by TrackAPI.
+type Track struct {
+	Id   int
+	Name string
+}
+
+// The methods of a MediaList.
+type MediaListAPI interface {
+	Add(m MediaAPI) error
+	Insert(m MediaAPI, pos int) error
+	Remove(pos int) error
+	Count() (int, error)
+	At(pos int) (MediaAPI, error)
+	Index(m MediaAPI) (int, error)
+	Lock() error
+	Unlock() error
+	Subscribe(types ...EventType) (<-chan *Event, func(), error)
+	Release() error
+	Close() error
+}
+
+// The methods of a ListPlayer.
+type ListPlayerAPI interface {
+	Set(l MediaListAPI) error
+	Replace(p PlayerAPI) error
+	Play() error
+	Pause() error
+	Stop() error
+	Next() error
+	Prev() error
+	PlayAt(pos int) error
+	PlayItem(m MediaAPI) error
+	IsPlaying() (bool, error)
+	State() (MediaState, error)
+	SetMode(pm PlaybackMode) error
+	Subscribe(types ...EventType) (<-chan *Event, func(), error)
+	Release() error
+	Close() error
+}
+
+// The methods of an Instance which create playback objects.
+type InstanceAPI interface {
+	OpenMediaUri(uri string) (MediaAPI, error)
+	OpenMediaFile(path string) (MediaAPI, error)
+	NewPlayer() (PlayerAPI, error)
+	NewList() (MediaListAPI, error)
+	NewListPlayer() (ListPlayerAPI, error)
+	Release() error
+	Close() error
+}
+
+// Error returned for invalid use of an object, such as a call on a released
+// handle.
+type VLCError struct {
+	What string
+}
+
+func (e *VLCError) Error() string {
+	return e.What
+}
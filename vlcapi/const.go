@@ -0,0 +1,290 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlcapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+type EventType int
+
+const (
+	MediaMetaChanged EventType = iota
+	MediaSubItemAdded
+	MediaDurationChanged
+	MediaParsedChanged
+	MediaFreed
+	MediaStateChanged
+	MediaSubItemTreeAdded
+)
+
+const (
+	MediaPlayerMediaChanged EventType = 0x100 + iota
+	MediaPlayerNothingSpecial
+	MediaPlayerOpening
+	MediaPlayerBuffering
+	MediaPlayerPlaying
+	MediaPlayerPaused
+	MediaPlayerStopped
+	MediaPlayerForward
+	MediaPlayerBackward
+	MediaPlayerEndReached
+	MediaPlayerEncounteredError
+	MediaPlayerTimeChanged
+	MediaPlayerPositionChanged
+	MediaPlayerSeekableChanged
+	MediaPlayerPausableChanged
+	MediaPlayerTitleChanged
+	MediaPlayerSnapshotTaken
+	MediaPlayerLengthChanged
+	MediaPlayerVout
+	MediaPlayerScrambledChanged
+	MediaPlayerESAdded
+	MediaPlayerESDeleted
+	MediaPlayerESSelected
+	MediaPlayerCorked
+	MediaPlayerUncorked
+	MediaPlayerMuted
+	MediaPlayerUnmuted
+	MediaPlayerAudioVolume
+	MediaPlayerAudioDevice
+	MediaPlayerChapterChanged
+)
+
+const (
+	MediaListItemAdded EventType = 0x200 + iota
+	MediaListWillAddItem
+	MediaListItemDeleted
+	MediaListWillDeleteItem
+	MediaListEndReached
+)
+
+const (
+	MediaListViewItemAdded EventType = 0x300 + iota
+	MediaListViewWillAddItem
+	MediaListViewItemDeleted
+	MediaListViewWillDeleteItem
+)
+
+const (
+	MediaListPlayerPlayed EventType = 0x400 + iota
+	MediaListPlayerNextItemSet
+	MediaListPlayerStopped
+)
+
+const (
+	MediaDiscovererStarted EventType = 0x500 + iota
+	MediaDiscovererEnded
+)
+
+const (
+	VlmMediaAdded EventType = 0x600 + iota
+	VlmMediaRemoved
+	VlmMediaChanged
+	VlmMediaInstanceStarted
+	VlmMediaInstanceStopped
+	VlmMediaInstanceStatusInit
+	VlmMediaInstanceStatusOpening
+	VlmMediaInstanceStatusPlaying
+	VlmMediaInstanceStatusPause
+	VlmMediaInstanceStatusEnd
+	VlmMediaInstanceStatusError
+)
+
+// String returns the name of the event type, as libvlc_event_type_name()
+// does.
+func (this EventType) String() string {
+	if s, ok := eventNames[this]; ok {
+		return s
+	}
+	return "Unknown Event"
+}
+
+var eventNames = map[EventType]string{
+	MediaMetaChanged:              "MediaMetaChanged",
+	MediaSubItemAdded:             "MediaSubItemAdded",
+	MediaDurationChanged:          "MediaDurationChanged",
+	MediaParsedChanged:            "MediaParsedChanged",
+	MediaFreed:                    "MediaFreed",
+	MediaStateChanged:             "MediaStateChanged",
+	MediaSubItemTreeAdded:         "MediaSubItemTreeAdded",
+	MediaPlayerMediaChanged:       "MediaPlayerMediaChanged",
+	MediaPlayerNothingSpecial:     "MediaPlayerNothingSpecial",
+	MediaPlayerOpening:            "MediaPlayerOpening",
+	MediaPlayerBuffering:          "MediaPlayerBuffering",
+	MediaPlayerPlaying:            "MediaPlayerPlaying",
+	MediaPlayerPaused:             "MediaPlayerPaused",
+	MediaPlayerStopped:            "MediaPlayerStopped",
+	MediaPlayerForward:            "MediaPlayerForward",
+	MediaPlayerBackward:           "MediaPlayerBackward",
+	MediaPlayerEndReached:         "MediaPlayerEndReached",
+	MediaPlayerEncounteredError:   "MediaPlayerEncounteredError",
+	MediaPlayerTimeChanged:        "MediaPlayerTimeChanged",
+	MediaPlayerPositionChanged:    "MediaPlayerPositionChanged",
+	MediaPlayerSeekableChanged:    "MediaPlayerSeekableChanged",
+	MediaPlayerPausableChanged:    "MediaPlayerPausableChanged",
+	MediaPlayerTitleChanged:       "MediaPlayerTitleChanged",
+	MediaPlayerSnapshotTaken:      "MediaPlayerSnapshotTaken",
+	MediaPlayerLengthChanged:      "MediaPlayerLengthChanged",
+	MediaPlayerVout:               "MediaPlayerVout",
+	MediaPlayerScrambledChanged:   "MediaPlayerScrambledChanged",
+	MediaPlayerESAdded:            "MediaPlayerESAdded",
+	MediaPlayerESDeleted:          "MediaPlayerESDeleted",
+	MediaPlayerESSelected:         "MediaPlayerESSelected",
+	MediaPlayerCorked:             "MediaPlayerCorked",
+	MediaPlayerUncorked:           "MediaPlayerUncorked",
+	MediaPlayerMuted:              "MediaPlayerMuted",
+	MediaPlayerUnmuted:            "MediaPlayerUnmuted",
+	MediaPlayerAudioVolume:        "MediaPlayerAudioVolume",
+	MediaPlayerAudioDevice:        "MediaPlayerAudioDevice",
+	MediaPlayerChapterChanged:     "MediaPlayerChapterChanged",
+	MediaListItemAdded:            "MediaListItemAdded",
+	MediaListWillAddItem:          "MediaListWillAddItem",
+	MediaListItemDeleted:          "MediaListItemDeleted",
+	MediaListWillDeleteItem:       "MediaListWillDeleteItem",
+	MediaListEndReached:           "MediaListEndReached",
+	MediaListViewItemAdded:        "MediaListViewItemAdded",
+	MediaListViewWillAddItem:      "MediaListViewWillAddItem",
+	MediaListViewItemDeleted:      "MediaListViewItemDeleted",
+	MediaListViewWillDeleteItem:   "MediaListViewWillDeleteItem",
+	MediaListPlayerPlayed:         "MediaListPlayerPlayed",
+	MediaListPlayerNextItemSet:    "MediaListPlayerNextItemSet",
+	MediaListPlayerStopped:        "MediaListPlayerStopped",
+	MediaDiscovererStarted:        "MediaDiscovererStarted",
+	MediaDiscovererEnded:          "MediaDiscovererEnded",
+	VlmMediaAdded:                 "VlmMediaAdded",
+	VlmMediaRemoved:               "VlmMediaRemoved",
+	VlmMediaChanged:               "VlmMediaChanged",
+	VlmMediaInstanceStarted:       "VlmMediaInstanceStarted",
+	VlmMediaInstanceStopped:       "VlmMediaInstanceStopped",
+	VlmMediaInstanceStatusInit:    "VlmMediaInstanceStatusInit",
+	VlmMediaInstanceStatusOpening: "VlmMediaInstanceStatusOpening",
+	VlmMediaInstanceStatusPlaying: "VlmMediaInstanceStatusPlaying",
+	VlmMediaInstanceStatusPause:   "VlmMediaInstanceStatusPause",
+	VlmMediaInstanceStatusEnd:     "VlmMediaInstanceStatusEnd",
+	VlmMediaInstanceStatusError:   "VlmMediaInstanceStatusError",
+}
+
+type PlaybackMode uint8
+
+const (
+	PMDefault PlaybackMode = iota
+	PMLoop
+	PMRepeat
+)
+
+type MetaProperty uint8
+
+const (
+	MPTitle MetaProperty = iota
+	MPArtist
+	MPGenre
+	MPCopyright
+	MPAlbum
+	MPTrackNumber
+	MPDescription
+	MPRating
+	MPDate
+	MPSetting
+	MPURL
+	MPLanguage
+	MPNowPlaying
+	MPPublisher
+	MPEncodedBy
+	MPArtworkURL
+	MPTrackID
+)
+
+type MediaState uint8
+
+const (
+	MSNothingSpecial MediaState = iota
+	MSOpening
+	MSBuffering
+	MSPlaying
+	MSPaused
+	MSStopped
+	MSEnded
+	MSError
+)
+
+func (this MediaState) String() string {
+	switch this {
+	case MSNothingSpecial:
+		return "nothing special"
+	case MSOpening:
+		return "opening"
+	case MSBuffering:
+		return "buffering"
+	case MSPlaying:
+		return "playing"
+	case MSPaused:
+		return "paused"
+	case MSStopped:
+		return "stopped"
+	case MSEnded:
+		return "ended"
+	case MSError:
+		return "error"
+	}
+	return fmt.Sprintf("MediaState(%d)", uint8(this))
+}
+
+type ParsedStatus uint8
+
+const (
+	PSNone ParsedStatus = iota
+	PSSkipped
+	PSFailed
+	PSTimeout
+	PSDone
+)
+
+// How seeks within a media are resolved. libVLC 3.0 fixes this when the
+// media is opened, through the :input-fast-seek option.
+type SeekMode int
+
+const (
+	SeekPrecise SeekMode = iota // Decode up to the exact time. Slower.
+	SeekFast                    // Jump to the nearest key frame.
+)
+
+func (this SeekMode) String() string {
+	switch this {
+	case SeekPrecise:
+		return "precise"
+	case SeekFast:
+		return "fast"
+	}
+	return fmt.Sprintf("SeekMode(%d)", int(this))
+}
+
+// MediaSeekMode returns the seek mode selected by options, as returned by
+// MediaAPI.Options(). The last :input-fast-seek or :no-input-fast-seek
+// option counts; without either, it is SeekPrecise.
+func MediaSeekMode(options []string) SeekMode {
+	mode := SeekPrecise
+
+	for _, o := range options {
+		switch strings.TrimLeft(o, ":-") {
+		case "input-fast-seek":
+			mode = SeekFast
+		case "no-input-fast-seek":
+			mode = SeekPrecise
+		}
+	}
+
+	return mode
+}
+
+type TrackType int
+
+const (
+	TTUnknown TrackType = -1
+	TTAudio   TrackType = 0
+	TTVideo   TrackType = 1
+	TTText    TrackType = 2
+)
@@ -0,0 +1,58 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlcapi
+
+import "testing"
+
+func TestEventTypeString(t *testing.T) {
+	for _, tt := range []struct {
+		t    EventType
+		want string
+	}{
+		{MediaMetaChanged, "MediaMetaChanged"},
+		{MediaPlayerPlaying, "MediaPlayerPlaying"},
+		{MediaPlayerChapterChanged, "MediaPlayerChapterChanged"},
+		{MediaListViewWillDeleteItem, "MediaListViewWillDeleteItem"},
+		{MediaListPlayerNextItemSet, "MediaListPlayerNextItemSet"},
+		{VlmMediaInstanceStatusError, "VlmMediaInstanceStatusError"},
+		{EventType(0x700), "Unknown Event"},
+	} {
+		if have := tt.t.String(); have != tt.want {
+			t.Fatalf("EventType(%#x): have %q, want %q", int(tt.t), have, tt.want)
+		}
+	}
+}
+
+func TestMediaStateString(t *testing.T) {
+	for _, tt := range []struct {
+		s    MediaState
+		want string
+	}{
+		{MSNothingSpecial, "nothing special"},
+		{MSPlaying, "playing"},
+		{MSError, "error"},
+		{MediaState(42), "MediaState(42)"},
+	} {
+		if have := tt.s.String(); have != tt.want {
+			t.Fatalf("MediaState(%d): have %q, want %q", tt.s, have, tt.want)
+		}
+	}
+}
+
+func TestMediaSeekMode(t *testing.T) {
+	for _, tt := range []struct {
+		options []string
+		want    SeekMode
+	}{
+		{nil, SeekPrecise},
+		{[]string{":no-audio", ":input-fast-seek"}, SeekFast},
+		{[]string{"--input-fast-seek"}, SeekFast},
+		{[]string{":input-fast-seek", ":no-input-fast-seek"}, SeekPrecise},
+	} {
+		if have := MediaSeekMode(tt.options); have != tt.want {
+			t.Fatalf("MediaSeekMode(%q): have %v, want %v", tt.options, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlcapi
+
+import "time"
+
+// Number of events buffered by a channel returned from a Subscribe() method.
+const EventBufferSize = 64
+
+// Generic event type. Use a type switch on Event.Payload() to access the
+// event data. Events which carry no data beyond their type have a nil payload.
+type Event struct {
+	Type    EventType
+	payload interface{}
+}
+
+// NewEvent creates an event of the given type with the given payload, which
+// should be the payload type documented for that event.
+func NewEvent(t EventType, payload interface{}) *Event {
+	return &Event{Type: t, payload: payload}
+}
+
+// Payload returns the event data as one of the *Event structs in this
+// package, or nil if the event type carries no data.
+//
+// Media values in payloads from package vlc are *vlc.Media handles. Those
+// passed to an EventHandler are owned by libvlc and are only valid until the
+// handler returns; convert them to *vlc.Media and call Retain() to keep them.
+// Those read from a Subscribe() channel have been retained for the reader,
+// which must Release() them.
+func (this *Event) Payload() interface{} { return this.payload }
+
+// Media returns the media in the event's payload, or nil if there is none.
+func (this *Event) Media() MediaAPI {
+	switch p := this.payload.(type) {
+	case *SubItemAddedEvent:
+		return p.Media
+	case *MediaFreedEvent:
+		return p.Media
+	case *MediaChangedEvent:
+		return p.Media
+	case *ListItemEvent:
+		return p.Media
+	case *NextItemSetEvent:
+		return p.Media
+	}
+	return nil
+}
+
+// Payload for MediaMetaChanged.
+type MetaChangedEvent struct {
+	Meta MetaProperty
+}
+
+// Payload for MediaSubItemAdded and MediaSubItemTreeAdded.
+type SubItemAddedEvent struct {
+	Media MediaAPI
+}
+
+// Payload for MediaDurationChanged.
+type DurationChangedEvent struct {
+	Duration time.Duration
+}
+
+// Payload for MediaParsedChanged.
+type ParsedChangedEvent struct {
+	Status ParsedStatus
+}
+
+// Payload for MediaFreed.
+type MediaFreedEvent struct {
+	Media MediaAPI
+}
+
+// Payload for MediaStateChanged, as well as for the MediaPlayer state events
+// (MediaPlayerOpening, MediaPlayerPlaying, MediaPlayerEndReached, etc).
+type StateChangedEvent struct {
+	State MediaState
+}
+
+// Payload for MediaPlayerMediaChanged.
+type MediaChangedEvent struct {
+	Media MediaAPI
+}
+
+// Payload for MediaPlayerBuffering. Cache is the buffer fill level in percent.
+type BufferingEvent struct {
+	Cache float32
+}
+
+// Payload for MediaPlayerTimeChanged.
+type TimeChangedEvent struct {
+	Time time.Duration
+}
+
+// Payload for MediaPlayerPositionChanged.
+type PositionChangedEvent struct {
+	Position float32
+}
+
+// Payload for MediaPlayerSeekableChanged.
+type SeekableChangedEvent struct {
+	Seekable bool
+}
+
+// Payload for MediaPlayerPausableChanged.
+type PausableChangedEvent struct {
+	Pausable bool
+}
+
+// Payload for MediaPlayerScrambledChanged.
+type ScrambledChangedEvent struct {
+	Scrambled bool
+}
+
+// Payload for MediaPlayerTitleChanged.
+type TitleChangedEvent struct {
+	Title int
+}
+
+// Payload for MediaPlayerChapterChanged.
+type ChapterChangedEvent struct {
+	Chapter int
+}
+
+// Payload for MediaPlayerSnapshotTaken.
+type SnapshotTakenEvent struct {
+	Path string
+}
+
+// Payload for MediaPlayerLengthChanged.
+type LengthChangedEvent struct {
+	Length time.Duration
+}
+
+// Payload for MediaPlayerVout. Count is the number of video outputs.
+type VoutEvent struct {
+	Count int
+}
+
+// Payload for MediaPlayerESAdded, MediaPlayerESDeleted and MediaPlayerESSelected.
+type ESChangedEvent struct {
+	Type TrackType
+	Id   int
+}
+
+// Payload for MediaPlayerAudioVolume.
+type AudioVolumeEvent struct {
+	Volume float32
+}
+
+// Payload for MediaPlayerAudioDevice.
+type AudioDeviceEvent struct {
+	Device string
+}
+
+// Payload for the MediaList* and MediaListView* item events.
+type ListItemEvent struct {
+	Media MediaAPI
+	Index int
+}
+
+// Payload for MediaListPlayerNextItemSet.
+type NextItemSetEvent struct {
+	Media MediaAPI
+}
+
+// Payload for the Vlm* events.
+type VlmMediaEvent struct {
+	Media    string
+	Instance string
+}
@@ -0,0 +1,228 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlctest
+
+import (
+	"github.com/jteeuwen/go-vlc/vlcapi"
+)
+
+// A fake list player. It implements vlcapi.ListPlayerAPI.
+//
+// When its player reaches the end of an item, the next one is played
+// according to the playback mode. At the end of the list, in vlcapi.PMDefault
+// mode, MediaListPlayerPlayed is emitted and playback stops.
+type ListPlayer struct {
+	b      *Backend
+	list   *MediaList
+	player *Player
+	index  int // Index of the current item, or -1.
+	mode   vlcapi.PlaybackMode
+	events hub
+}
+
+var _ vlcapi.ListPlayerAPI = (*ListPlayer)(nil)
+
+// Set sets the list to play, created by the same Backend.
+func (this *ListPlayer) Set(l vlcapi.MediaListAPI) error {
+	ml, ok := l.(*MediaList)
+	if !ok || ml == nil {
+		return &vlcapi.VLCError{What: "MediaList was not created by vlctest"}
+	}
+
+	this.b.m.Lock()
+	this.list = ml
+	this.index = -1
+	this.b.m.Unlock()
+	return nil
+}
+
+// Replace replaces the player used to play the list.
+func (this *ListPlayer) Replace(p vlcapi.PlayerAPI) error {
+	pp, ok := p.(*Player)
+	if !ok || pp == nil {
+		return &vlcapi.VLCError{What: "Player was not created by vlctest"}
+	}
+
+	this.b.m.Lock()
+	this.setPlayer(pp)
+	this.b.m.Unlock()
+	return nil
+}
+
+func (this *ListPlayer) setPlayer(p *Player) {
+	if this.player != nil {
+		this.player.onEnd = nil
+	}
+
+	this.player = p
+	p.onEnd = this.ended
+}
+
+// Play starts playing the list at the first item, or resumes playback.
+func (this *ListPlayer) Play() error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if this.index < 0 {
+		return this.playAt(0)
+	}
+
+	return this.player.play()
+}
+
+// Pause toggles between playing and paused.
+func (this *ListPlayer) Pause() error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.player.togglePause(this.player.state == vlcapi.MSPlaying)
+}
+
+// Stop stops playback and emits MediaListPlayerStopped.
+func (this *ListPlayer) Stop() error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	this.player.stop()
+	this.events.emit(vlcapi.MediaListPlayerStopped, nil)
+	return nil
+}
+
+// Next plays the next item. In vlcapi.PMLoop mode, the first item follows the
+// last one.
+func (this *ListPlayer) Next() error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if i, ok := this.step(1); ok {
+		return this.playAt(i)
+	}
+
+	return &vlcapi.VLCError{What: "No next item"}
+}
+
+// Prev plays the previous item. In vlcapi.PMLoop mode, the last item precedes
+// the first one.
+func (this *ListPlayer) Prev() error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if i, ok := this.step(-1); ok {
+		return this.playAt(i)
+	}
+
+	return &vlcapi.VLCError{What: "No previous item"}
+}
+
+// PlayAt plays the item at the given index.
+func (this *ListPlayer) PlayAt(pos int) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.playAt(pos)
+}
+
+// PlayItem plays the given item of the list.
+func (this *ListPlayer) PlayItem(m vlcapi.MediaAPI) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if this.list == nil {
+		return &vlcapi.VLCError{What: "ListPlayer has no list"}
+	}
+
+	i := this.list.index(m)
+	if i < 0 {
+		return &vlcapi.VLCError{What: "Media is not in the list"}
+	}
+
+	return this.playAt(i)
+}
+
+// IsPlaying returns true if the player is in MSPlaying.
+func (this *ListPlayer) IsPlaying() (bool, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.player.state == vlcapi.MSPlaying, nil
+}
+
+// State returns the state of the player.
+func (this *ListPlayer) State() (vlcapi.MediaState, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.player.state, nil
+}
+
+// SetMode sets the playback mode.
+func (this *ListPlayer) SetMode(pm vlcapi.PlaybackMode) error {
+	this.b.m.Lock()
+	this.mode = pm
+	this.b.m.Unlock()
+	return nil
+}
+
+// Subscribe subscribes to MediaListPlayerPlayed, MediaListPlayerNextItemSet
+// and MediaListPlayerStopped events.
+func (this *ListPlayer) Subscribe(types ...vlcapi.EventType) (<-chan *vlcapi.Event, func(), error) {
+	return this.events.subscribe(this.b, types)
+}
+
+// Release stops playback.
+func (this *ListPlayer) Release() error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	this.player.stop()
+	this.player.onEnd = nil
+	return nil
+}
+
+// Close is the same as Release.
+func (this *ListPlayer) Close() error { return this.Release() }
+
+func (this *ListPlayer) playAt(pos int) error {
+	if this.list == nil {
+		return &vlcapi.VLCError{What: "ListPlayer has no list"}
+	}
+
+	if pos < 0 || pos >= len(this.list.items) {
+		return &vlcapi.VLCError{What: "Index out of range"}
+	}
+
+	this.index = pos
+	m := this.list.items[pos]
+	this.player.setMedia(m)
+	this.events.emit(vlcapi.MediaListPlayerNextItemSet, &vlcapi.NextItemSetEvent{Media: m})
+	return this.player.play()
+}
+
+// step returns the index of the item d positions away from the current one.
+func (this *ListPlayer) step(d int) (int, bool) {
+	if this.list == nil || len(this.list.items) == 0 {
+		return 0, false
+	}
+
+	n := len(this.list.items)
+	i := this.index + d
+
+	if this.mode == vlcapi.PMLoop {
+		return (i%n + n) % n, true
+	}
+
+	return i, i >= 0 && i < n
+}
+
+// ended is called by the player when it reaches the end of an item.
+func (this *ListPlayer) ended() {
+	if this.mode == vlcapi.PMRepeat {
+		this.playAt(this.index)
+		return
+	}
+
+	if i, ok := this.step(1); ok {
+		this.playAt(i)
+		return
+	}
+
+	this.events.emit(vlcapi.MediaListPlayerPlayed, nil)
+}
@@ -0,0 +1,229 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlctest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jteeuwen/go-vlc/vlcapi"
+)
+
+// A fake media. It implements vlcapi.MediaAPI.
+type Media struct {
+	b       *Backend
+	mrl     string
+	info    MediaInfo
+	meta    map[vlcapi.MetaProperty]string
+	options []string
+	state   vlcapi.MediaState
+	events  hub
+}
+
+var _ vlcapi.MediaAPI = (*Media)(nil)
+
+// Mrl returns the location the media was opened with.
+func (this *Media) Mrl() string { return this.mrl }
+
+// Meta returns the metadata registered for the media, or set with SetMeta().
+func (this *Media) Meta(mp vlcapi.MetaProperty) string {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.meta[mp]
+}
+
+// SetMeta sets a metadata property and emits MediaMetaChanged.
+func (this *Media) SetMeta(mp vlcapi.MetaProperty, v string) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	this.meta[mp] = v
+	this.events.emit(vlcapi.MediaMetaChanged, &vlcapi.MetaChangedEvent{Meta: mp})
+}
+
+// AddOption records the option. It has no effect on playback.
+func (this *Media) AddOption(options string) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	this.options = append(this.options, options)
+	return nil
+}
+
+// Options returns the options added with AddOption().
+func (this *Media) Options() []string {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return append([]string(nil), this.options...)
+}
+
+// Duration returns the registered length in milliseconds, or -1 if it is
+// zero.
+func (this *Media) Duration() int64 {
+	if this.info.Length <= 0 {
+		return -1
+	}
+	return this.info.Length.Milliseconds()
+}
+
+// Length returns the registered length, or an error if it is zero.
+func (this *Media) Length() (time.Duration, error) {
+	if this.info.Length <= 0 {
+		return 0, &vlcapi.VLCError{What: "Media duration is unknown"}
+	}
+	return this.info.Length, nil
+}
+
+// State returns the state of the player playing the media.
+func (this *Media) State() vlcapi.MediaState {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.state
+}
+
+// ParsedStatus returns vlcapi.PSDone, as fake media need no parsing.
+func (this *Media) ParsedStatus() vlcapi.ParsedStatus { return vlcapi.PSDone }
+
+// Subscribe subscribes to MediaMetaChanged and MediaStateChanged events.
+func (this *Media) Subscribe(types ...vlcapi.EventType) (<-chan *vlcapi.Event, func(), error) {
+	return this.events.subscribe(this.b, types)
+}
+
+// Release does nothing. It exists to implement vlcapi.MediaAPI.
+func (this *Media) Release() error { return nil }
+
+// Close is the same as Release.
+func (this *Media) Close() error { return this.Release() }
+
+func (this *Media) setState(s vlcapi.MediaState) {
+	this.state = s
+	this.events.emit(vlcapi.MediaStateChanged, &vlcapi.StateChangedEvent{State: s})
+}
+
+// A fake media list. It implements vlcapi.MediaListAPI.
+type MediaList struct {
+	b      *Backend
+	lock   sync.Mutex // Held between Lock() and Unlock().
+	items  []*Media
+	events hub
+}
+
+var _ vlcapi.MediaListAPI = (*MediaList)(nil)
+
+// Add appends a media created by the same Backend and emits
+// MediaListItemAdded.
+func (this *MediaList) Add(m vlcapi.MediaAPI) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.insert(m, len(this.items))
+}
+
+// Insert inserts a media created by the same Backend at pos and emits
+// MediaListItemAdded.
+func (this *MediaList) Insert(m vlcapi.MediaAPI, pos int) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.insert(m, pos)
+}
+
+func (this *MediaList) insert(m vlcapi.MediaAPI, pos int) error {
+	mm, err := toMedia(m)
+	if err != nil {
+		return err
+	}
+
+	if pos < 0 || pos > len(this.items) {
+		return &vlcapi.VLCError{What: "Index out of range"}
+	}
+
+	this.items = append(this.items, nil)
+	copy(this.items[pos+1:], this.items[pos:])
+	this.items[pos] = mm
+
+	this.events.emit(vlcapi.MediaListItemAdded, &vlcapi.ListItemEvent{Media: mm, Index: pos})
+	return nil
+}
+
+// Remove removes the media at pos and emits MediaListItemDeleted.
+func (this *MediaList) Remove(pos int) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if pos < 0 || pos >= len(this.items) {
+		return &vlcapi.VLCError{What: "Index out of range"}
+	}
+
+	m := this.items[pos]
+	this.items = append(this.items[:pos], this.items[pos+1:]...)
+	this.events.emit(vlcapi.MediaListItemDeleted, &vlcapi.ListItemEvent{Media: m, Index: pos})
+	return nil
+}
+
+// Count returns the number of items in the list.
+func (this *MediaList) Count() (int, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return len(this.items), nil
+}
+
+// At returns the media at pos.
+func (this *MediaList) At(pos int) (vlcapi.MediaAPI, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if pos < 0 || pos >= len(this.items) {
+		return nil, &vlcapi.VLCError{What: "Index out of range"}
+	}
+
+	return this.items[pos], nil
+}
+
+// Index returns the position of m in the list, or -1 if it is not in it.
+func (this *MediaList) Index(m vlcapi.MediaAPI) (int, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.index(m), nil
+}
+
+func (this *MediaList) index(m vlcapi.MediaAPI) int {
+	for i, v := range this.items {
+		if vlcapi.MediaAPI(v) == m {
+			return i
+		}
+	}
+	return -1
+}
+
+// Lock locks the list for the caller. The list's own methods do not need it.
+func (this *MediaList) Lock() error {
+	this.lock.Lock()
+	return nil
+}
+
+// Unlock releases the lock taken by Lock().
+func (this *MediaList) Unlock() error {
+	this.lock.Unlock()
+	return nil
+}
+
+// Subscribe subscribes to MediaListItemAdded and MediaListItemDeleted
+// events.
+func (this *MediaList) Subscribe(types ...vlcapi.EventType) (<-chan *vlcapi.Event, func(), error) {
+	return this.events.subscribe(this.b, types)
+}
+
+// Release does nothing. It exists to implement vlcapi.MediaListAPI.
+func (this *MediaList) Release() error { return nil }
+
+// Close is the same as Release.
+func (this *MediaList) Close() error { return this.Release() }
+
+// toMedia returns the fake media behind m.
+func toMedia(m vlcapi.MediaAPI) (*Media, error) {
+	if mm, ok := m.(*Media); ok && mm != nil {
+		return mm, nil
+	}
+	return nil, &vlcapi.VLCError{What: "Media was not created by vlctest"}
+}
@@ -0,0 +1,591 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlctest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jteeuwen/go-vlc/vlcapi"
+)
+
+// A fake player. It implements vlcapi.PlayerAPI.
+//
+// Play() moves through MSOpening to MSPlaying, or to MSError for media
+// registered with Fail set. A player stays in MSPlaying until it is paused
+// or stopped, or until Backend.Advance() reaches the end of its media.
+type Player struct {
+	b      *Backend
+	media  *Media
+	state  vlcapi.MediaState
+	time   time.Duration
+	rate   float32
+	volume int
+	mute   bool
+	events hub
+
+	// Called with the backend lock held when the end of the media is
+	// reached. Used by ListPlayer.
+	onEnd func()
+}
+
+var _ vlcapi.PlayerAPI = (*Player)(nil)
+
+// Events emitted for each state.
+var stateEvents = map[vlcapi.MediaState]vlcapi.EventType{
+	vlcapi.MSNothingSpecial: vlcapi.MediaPlayerNothingSpecial,
+	vlcapi.MSOpening:        vlcapi.MediaPlayerOpening,
+	vlcapi.MSBuffering:      vlcapi.MediaPlayerBuffering,
+	vlcapi.MSPlaying:        vlcapi.MediaPlayerPlaying,
+	vlcapi.MSPaused:         vlcapi.MediaPlayerPaused,
+	vlcapi.MSStopped:        vlcapi.MediaPlayerStopped,
+	vlcapi.MSEnded:          vlcapi.MediaPlayerEndReached,
+	vlcapi.MSError:          vlcapi.MediaPlayerEncounteredError,
+}
+
+// Media returns the current media, or nil if there is none.
+func (this *Player) Media() (vlcapi.MediaAPI, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if this.media == nil {
+		return nil, nil
+	}
+
+	return this.media, nil
+}
+
+// SetMedia stops playback and sets a media created by the same Backend. It
+// emits MediaPlayerMediaChanged.
+func (this *Player) SetMedia(m vlcapi.MediaAPI) error {
+	mm, err := toMedia(m)
+	if err != nil {
+		return err
+	}
+
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	this.setMedia(mm)
+	return nil
+}
+
+func (this *Player) setMedia(m *Media) {
+	if this.active() {
+		this.stop()
+	}
+
+	this.media = m
+	this.state = vlcapi.MSNothingSpecial
+	this.time = 0
+	this.events.emit(vlcapi.MediaPlayerMediaChanged, &vlcapi.MediaChangedEvent{Media: m})
+}
+
+// Play starts or resumes playback.
+func (this *Player) Play() error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.play()
+}
+
+func (this *Player) play() error {
+	if this.media == nil {
+		return &vlcapi.VLCError{What: "Player has no media"}
+	}
+
+	switch this.state {
+	case vlcapi.MSPlaying:
+		return nil
+	case vlcapi.MSPaused:
+		this.setState(vlcapi.MSPlaying)
+		return nil
+	}
+
+	info := this.media.info
+	this.time = 0
+	this.setState(vlcapi.MSOpening)
+
+	if info.Fail {
+		this.setState(vlcapi.MSError)
+		return nil
+	}
+
+	this.events.emit(vlcapi.MediaPlayerLengthChanged, &vlcapi.LengthChangedEvent{Length: info.Length})
+	this.events.emit(vlcapi.MediaPlayerSeekableChanged, &vlcapi.SeekableChangedEvent{Seekable: !info.Unseekable})
+	this.events.emit(vlcapi.MediaPlayerPausableChanged, &vlcapi.PausableChangedEvent{Pausable: !info.Unpausable})
+	this.setState(vlcapi.MSPlaying)
+	return nil
+}
+
+// Pause toggles between playing and paused.
+func (this *Player) Pause() error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.togglePause(this.state == vlcapi.MSPlaying)
+}
+
+// TogglePause pauses or resumes playback.
+func (this *Player) TogglePause(pause bool) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.togglePause(pause)
+}
+
+func (this *Player) togglePause(pause bool) error {
+	if this.media == nil || this.media.info.Unpausable {
+		return nil
+	}
+
+	switch {
+	case pause && this.state == vlcapi.MSPlaying:
+		this.setState(vlcapi.MSPaused)
+	case !pause && this.state == vlcapi.MSPaused:
+		this.setState(vlcapi.MSPlaying)
+	}
+
+	return nil
+}
+
+// Stop stops playback and rewinds the media.
+func (this *Player) Stop() error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	this.stop()
+	return nil
+}
+
+func (this *Player) stop() {
+	if this.media == nil || this.state == vlcapi.MSNothingSpecial || this.state == vlcapi.MSStopped {
+		return
+	}
+
+	this.time = 0
+	this.setState(vlcapi.MSStopped)
+}
+
+// IsPlaying returns true if the player is in MSPlaying.
+func (this *Player) IsPlaying() bool {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.state == vlcapi.MSPlaying
+}
+
+// State returns the current state.
+func (this *Player) State() (vlcapi.MediaState, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.state, nil
+}
+
+// Length returns the length of the media in milliseconds, or -1 if there is
+// no media.
+func (this *Player) Length() (int64, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if this.media == nil {
+		return -1, nil
+	}
+
+	return this.media.info.Length.Milliseconds(), nil
+}
+
+// Time returns the playing time in milliseconds, or -1 if there is no
+// media.
+func (this *Player) Time() (int64, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if this.media == nil {
+		return -1, nil
+	}
+
+	return this.time.Milliseconds(), nil
+}
+
+// SetTime seeks to the given time in milliseconds, if the media is playing
+// or paused and seekable. It emits MediaPlayerTimeChanged and
+// MediaPlayerPositionChanged.
+func (this *Player) SetTime(v int64) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	this.seek(time.Duration(v) * time.Millisecond)
+}
+
+// Position returns the playing position between 0.0 and 1.0, or -1 if there
+// is no media.
+func (this *Player) Position() (float32, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if this.media == nil {
+		return -1, nil
+	}
+
+	return this.position(), nil
+}
+
+// SetPosition seeks to the given position between 0.0 and 1.0. See
+// SetTime().
+func (this *Player) SetPosition(v float32) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if this.media != nil {
+		this.seek(time.Duration(float64(v) * float64(this.media.info.Length)))
+	}
+}
+
+// Elapsed returns the playing time, or zero if there is no media.
+func (this *Player) Elapsed() (time.Duration, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if this.media == nil {
+		return 0, nil
+	}
+
+	return this.time, nil
+}
+
+// MediaLength returns the length of the media, or zero if there is no media.
+func (this *Player) MediaLength() (time.Duration, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if this.media == nil {
+		return 0, nil
+	}
+
+	return this.media.info.Length, nil
+}
+
+// Seek seeks to d like SetTime(), but returns an error if the media can not
+// be seeked in, or mode differs from the one set with the media's
+// :input-fast-seek option.
+func (this *Player) Seek(d time.Duration, mode vlcapi.SeekMode) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if d < 0 {
+		return &vlcapi.VLCError{What: "Seek time is negative"}
+	}
+
+	return this.seekChecked(d, mode)
+}
+
+// SeekBy seeks d away from the current time. Seeking back past the start
+// seeks to the start.
+func (this *Player) SeekBy(d time.Duration, mode vlcapi.SeekMode) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	t := this.time + d
+	if t < 0 {
+		t = 0
+	}
+
+	return this.seekChecked(t, mode)
+}
+
+// SeekPosition seeks to pos, between 0.0 and 1.0, like SetPosition(), but
+// returns errors like Seek().
+func (this *Player) SeekPosition(pos float32, mode vlcapi.SeekMode) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	if pos < 0 || pos > 1 {
+		return &vlcapi.VLCError{What: "Seek position out of range"}
+	}
+
+	if this.media == nil {
+		return &vlcapi.VLCError{What: "Media is not seekable"}
+	}
+
+	return this.seekChecked(time.Duration(float64(pos)*float64(this.media.info.Length)), mode)
+}
+
+func (this *Player) seekChecked(t time.Duration, mode vlcapi.SeekMode) error {
+	if !this.canSeek() {
+		return &vlcapi.VLCError{What: "Media is not seekable"}
+	}
+
+	if have := vlcapi.MediaSeekMode(this.media.options); have != mode {
+		return &vlcapi.VLCError{What: "Media is set up for " + have.String() + " seeking, not " + mode.String()}
+	}
+
+	this.seek(t)
+	return nil
+}
+
+func (this *Player) position() float32 {
+	if l := this.media.info.Length; l > 0 {
+		return float32(float64(this.time) / float64(l))
+	}
+	return 0
+}
+
+func (this *Player) seek(t time.Duration) {
+	if !this.canSeek() {
+		return
+	}
+
+	if t < 0 {
+		t = 0
+	}
+
+	this.time = t
+	this.progress()
+}
+
+// Rate returns the playback rate.
+func (this *Player) Rate() (float32, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.rate, nil
+}
+
+// SetRate sets the playback rate, which scales the time passed to
+// Backend.Advance().
+func (this *Player) SetRate(v float32) error {
+	if v <= 0 {
+		return &vlcapi.VLCError{What: "Invalid rate"}
+	}
+
+	this.b.m.Lock()
+	this.rate = v
+	this.b.m.Unlock()
+	return nil
+}
+
+// CanSeek returns true if the media is playing or paused and seekable.
+func (this *Player) CanSeek() (bool, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.canSeek(), nil
+}
+
+func (this *Player) canSeek() bool {
+	return this.active() && !this.media.info.Unseekable
+}
+
+// CanPause returns true if the media is playing or paused and pausable.
+func (this *Player) CanPause() (bool, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.active() && !this.media.info.Unpausable, nil
+}
+
+func (this *Player) active() bool {
+	return this.media != nil && (this.state == vlcapi.MSPlaying || this.state == vlcapi.MSPaused)
+}
+
+// Volume returns the volume in percent.
+func (this *Player) Volume() (int, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.volume, nil
+}
+
+// SetVolume sets the volume in percent, between 0 and 200, and emits
+// MediaPlayerAudioVolume.
+func (this *Player) SetVolume(v int) error {
+	if v < 0 || v > 200 {
+		return &vlcapi.VLCError{What: "Volume out of range"}
+	}
+
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	this.volume = v
+	this.events.emit(vlcapi.MediaPlayerAudioVolume, &vlcapi.AudioVolumeEvent{Volume: float32(v) / 100})
+	return nil
+}
+
+// IsMute returns true if audio is muted.
+func (this *Player) IsMute() (bool, error) {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+	return this.mute, nil
+}
+
+// SetMute mutes or unmutes audio and emits MediaPlayerMuted or
+// MediaPlayerUnmuted.
+func (this *Player) SetMute(toggle bool) error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	this.mute = toggle
+
+	if toggle {
+		this.events.emit(vlcapi.MediaPlayerMuted, nil)
+	} else {
+		this.events.emit(vlcapi.MediaPlayerUnmuted, nil)
+	}
+
+	return nil
+}
+
+// WaitForState blocks until the player reaches one of the given states, and
+// returns it, like vlc.Player.WaitForState(). The fake only changes state in
+// calls such as Play() or Backend.Advance(), so unless the player is already
+// in one of the states, another goroutine has to make those calls.
+func (this *Player) WaitForState(ctx context.Context, states ...vlcapi.MediaState) (vlcapi.MediaState, error) {
+	if len(states) == 0 {
+		return 0, &vlcapi.VLCError{What: "No states to wait for"}
+	}
+
+	events, cancel, _ := this.subscribeStates()
+	defer cancel()
+
+	s, _ := this.State()
+	if hasState(states, s) {
+		return s, nil
+	}
+
+	return this.waitForState(ctx, events, states)
+}
+
+// PlayAndWait starts playback like Play(), and returns nil once the player
+// is playing or has ended. As playback starts synchronously, it only blocks
+// if Play() leaves the player in another state.
+func (this *Player) PlayAndWait(ctx context.Context) error {
+	events, cancel, _ := this.subscribeStates()
+	defer cancel()
+
+	if err := this.Play(); err != nil {
+		return err
+	}
+
+	// A player which is already playing emits no events.
+	switch s, _ := this.State(); s {
+	case vlcapi.MSPlaying, vlcapi.MSEnded:
+		return nil
+	case vlcapi.MSError:
+		return this.waitError("Playback failed")
+	}
+
+	_, err := this.waitForState(ctx, events, []vlcapi.MediaState{vlcapi.MSPlaying, vlcapi.MSEnded})
+	return err
+}
+
+func (this *Player) subscribeStates() (<-chan *vlcapi.Event, func(), error) {
+	types := make([]vlcapi.EventType, 0, len(stateEvents))
+	for _, t := range stateEvents {
+		types = append(types, t)
+	}
+
+	return this.Subscribe(types...)
+}
+
+func (this *Player) waitForState(ctx context.Context, events <-chan *vlcapi.Event, states []vlcapi.MediaState) (vlcapi.MediaState, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			s, _ := this.State()
+			return s, ctx.Err()
+
+		case evt := <-events:
+			p, ok := evt.Payload().(*vlcapi.StateChangedEvent)
+			if !ok {
+				continue // MediaPlayerBuffering carries the cache level.
+			}
+
+			switch s := p.State; {
+			case hasState(states, s):
+				return s, nil
+			case s == vlcapi.MSError:
+				return s, this.waitError("Playback failed")
+			case s == vlcapi.MSEnded, s == vlcapi.MSStopped:
+				return s, this.waitError(fmt.Sprintf("Playback %s while waiting for %v", s, states))
+			}
+		}
+	}
+}
+
+// waitError returns an error with the given message and the MRL of the
+// player's media.
+func (this *Player) waitError(msg string) error {
+	if m, _ := this.Media(); m != nil {
+		msg += ": " + m.Mrl()
+	}
+
+	return &vlcapi.VLCError{What: msg}
+}
+
+func hasState(states []vlcapi.MediaState, s vlcapi.MediaState) bool {
+	for _, v := range states {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe subscribes to the given player events.
+func (this *Player) Subscribe(types ...vlcapi.EventType) (<-chan *vlcapi.Event, func(), error) {
+	return this.events.subscribe(this.b, types)
+}
+
+// Release stops playback. The player no longer receives time from
+// Backend.Advance().
+func (this *Player) Release() error {
+	this.b.m.Lock()
+	defer this.b.m.Unlock()
+
+	this.stop()
+
+	for i, p := range this.b.players {
+		if p == this {
+			this.b.players = append(this.b.players[:i], this.b.players[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// Close is the same as Release.
+func (this *Player) Close() error { return this.Release() }
+
+// setState changes the state of the player and its media, and emits the
+// corresponding events.
+func (this *Player) setState(s vlcapi.MediaState) {
+	this.state = s
+	this.events.emit(stateEvents[s], &vlcapi.StateChangedEvent{State: s})
+	this.media.setState(s)
+}
+
+// advance lets d pass, scaled by the rate.
+func (this *Player) advance(d time.Duration) {
+	if this.state != vlcapi.MSPlaying || d <= 0 {
+		return
+	}
+
+	this.time += time.Duration(float64(d) * float64(this.rate))
+	this.progress()
+}
+
+// progress emits the time and position events, and ends playback if the
+// end of the media has been reached.
+func (this *Player) progress() {
+	l := this.media.info.Length
+	if l > 0 && this.time > l {
+		this.time = l
+	}
+
+	this.events.emit(vlcapi.MediaPlayerTimeChanged, &vlcapi.TimeChangedEvent{Time: this.time})
+	this.events.emit(vlcapi.MediaPlayerPositionChanged, &vlcapi.PositionChangedEvent{Position: this.position()})
+
+	if l > 0 && this.time == l && this.state == vlcapi.MSPlaying {
+		this.setState(vlcapi.MSEnded)
+
+		if this.onEnd != nil {
+			this.onEnd()
+		}
+	}
+}
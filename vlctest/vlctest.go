@@ -0,0 +1,226 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+// Package vlctest provides a fake implementation of the vlcapi.InstanceAPI
+// family of interfaces, for testing code built on them without media files,
+// audio or video output.
+//
+// The fake never calls into libvlc, and does not need it or cgo to build, as
+// it only imports package vlcapi. Playback is simulated: state changes
+// happen synchronously in the calls which cause them, and time only passes
+// when Backend.Advance() is called. Events are delivered to subscribers
+// before the call which emitted them returns, so tests are deterministic:
+//
+//	b := vlctest.NewBackend()
+//	b.Register("file:///a.mp3", vlctest.MediaInfo{Length: 3 * time.Minute})
+//
+//	p, _ := b.NewPlayer()
+//	m, _ := b.OpenMediaFile("/a.mp3")
+//	p.SetMedia(m)
+//	p.Play()                   // MediaPlayerOpening, ..., MediaPlayerPlaying
+//	b.Advance(3 * time.Minute) // MediaPlayerTimeChanged, ..., MediaPlayerEndReached
+//
+// Event payloads are the same as those of libvlc. Their Media fields hold the
+// fake *Media concerned.
+package vlctest
+
+import (
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jteeuwen/go-vlc/vlcapi"
+)
+
+// Describes a fake media.
+type MediaInfo struct {
+	// Playing time. Media with a zero length, such as live streams, play
+	// until they are stopped.
+	Length time.Duration
+
+	Meta       map[vlcapi.MetaProperty]string
+	Unseekable bool // Player.SetTime() and Player.SetPosition() do nothing, seeks fail.
+	Unpausable bool // Player.Pause() and Player.TogglePause() do nothing.
+	Fail       bool // Playback fails with MediaPlayerEncounteredError.
+}
+
+// A fake libvlc instance. It implements vlcapi.InstanceAPI.
+//
+// All objects created by a Backend share its lock, so they may be used from
+// multiple goroutines.
+type Backend struct {
+	m       sync.Mutex
+	media   map[string]MediaInfo
+	players []*Player
+}
+
+var _ vlcapi.InstanceAPI = (*Backend)(nil)
+
+// NewBackend creates a new fake instance.
+func NewBackend() *Backend {
+	return &Backend{media: make(map[string]MediaInfo)}
+}
+
+// Register describes the media with the given MRL. Media which have not been
+// registered can be opened, but have no metadata and play until stopped.
+func (this *Backend) Register(mrl string, info MediaInfo) {
+	this.m.Lock()
+	this.media[mrl] = info
+	this.m.Unlock()
+}
+
+// Advance lets d pass for all players created by this backend, scaled by
+// their rate. Playing players emit MediaPlayerTimeChanged and
+// MediaPlayerPositionChanged once, and MediaPlayerEndReached if the end of
+// their media is reached. Time does not carry over to the next item of a
+// ListPlayer.
+func (this *Backend) Advance(d time.Duration) {
+	this.m.Lock()
+	defer this.m.Unlock()
+
+	for _, p := range this.players {
+		p.advance(d)
+	}
+}
+
+// OpenMediaUri opens the media with the given MRL.
+func (this *Backend) OpenMediaUri(uri string) (vlcapi.MediaAPI, error) {
+	if uri == "" {
+		return nil, &vlcapi.VLCError{What: "Media location is empty"}
+	}
+
+	this.m.Lock()
+	defer this.m.Unlock()
+	return this.newMedia(uri), nil
+}
+
+// OpenMediaFile opens the media at the given path. Its MRL is the path as a
+// file:// URI.
+func (this *Backend) OpenMediaFile(path string) (vlcapi.MediaAPI, error) {
+	if path == "" {
+		return nil, &vlcapi.VLCError{What: "Media path is empty"}
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+	return this.OpenMediaUri(u.String())
+}
+
+// NewPlayer creates a player without media.
+func (this *Backend) NewPlayer() (vlcapi.PlayerAPI, error) {
+	this.m.Lock()
+	defer this.m.Unlock()
+	return this.newPlayer(), nil
+}
+
+// NewList creates an empty media list.
+func (this *Backend) NewList() (vlcapi.MediaListAPI, error) {
+	return &MediaList{b: this}, nil
+}
+
+// NewListPlayer creates a list player with its own player and no list.
+func (this *Backend) NewListPlayer() (vlcapi.ListPlayerAPI, error) {
+	this.m.Lock()
+	defer this.m.Unlock()
+
+	lp := &ListPlayer{b: this, index: -1}
+	lp.setPlayer(this.newPlayer())
+	return lp, nil
+}
+
+// Release does nothing. It exists to implement vlcapi.InstanceAPI.
+func (this *Backend) Release() error { return nil }
+
+// Close is the same as Release.
+func (this *Backend) Close() error { return this.Release() }
+
+func (this *Backend) newMedia(mrl string) *Media {
+	info := this.media[mrl]
+
+	m := &Media{b: this, mrl: mrl, info: info, meta: make(map[vlcapi.MetaProperty]string)}
+	for k, v := range info.Meta {
+		m.meta[k] = v
+	}
+
+	return m
+}
+
+func (this *Backend) newPlayer() *Player {
+	p := &Player{b: this, rate: 1, volume: 100}
+	this.players = append(this.players, p)
+	return p
+}
+
+// Subscribers of a fake object. It is guarded by the lock of the Backend.
+type hub struct {
+	subs []*subscription
+}
+
+type subscription struct {
+	types map[vlcapi.EventType]bool
+	c     chan *vlcapi.Event
+}
+
+func (this *hub) subscribe(b *Backend, types []vlcapi.EventType) (<-chan *vlcapi.Event, func(), error) {
+	sub := &subscription{
+		types: make(map[vlcapi.EventType]bool, len(types)),
+		c:     make(chan *vlcapi.Event, vlcapi.EventBufferSize),
+	}
+
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	b.m.Lock()
+	this.subs = append(this.subs, sub)
+	b.m.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.m.Lock()
+			defer b.m.Unlock()
+
+			for i, s := range this.subs {
+				if s == sub {
+					this.subs = append(this.subs[:i], this.subs[i+1:]...)
+					break
+				}
+			}
+
+			close(sub.c)
+		})
+	}
+
+	return sub.c, cancel, nil
+}
+
+// emit delivers an event to all interested subscribers. As with libvlc, the
+// oldest buffered event is dropped if a subscriber's channel is full.
+func (this *hub) emit(t vlcapi.EventType, payload interface{}) {
+	for _, s := range this.subs {
+		if !s.types[t] {
+			continue
+		}
+
+		evt := vlcapi.NewEvent(t, payload)
+
+		for sent := false; !sent; {
+			select {
+			case s.c <- evt:
+				sent = true
+			default:
+				select {
+				case <-s.c:
+				default:
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlctest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jteeuwen/go-vlc/vlcapi"
+)
+
+// drain returns the types of all buffered events.
+func drain(c <-chan *vlcapi.Event) []vlcapi.EventType {
+	var list []vlcapi.EventType
+	for {
+		select {
+		case evt := <-c:
+			list = append(list, evt.Type)
+		default:
+			return list
+		}
+	}
+}
+
+func expect(t *testing.T, c <-chan *vlcapi.Event, want ...vlcapi.EventType) {
+	t.Helper()
+
+	have := drain(c)
+	if len(have) != len(want) {
+		t.Fatalf("events: have %v, want %v", have, want)
+	}
+
+	for i := range want {
+		if have[i] != want[i] {
+			t.Fatalf("events: have %v, want %v", have, want)
+		}
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	b := NewBackend()
+	b.Register("file:///a.ogg", MediaInfo{Length: 2 * time.Second})
+
+	var inst vlcapi.InstanceAPI = b
+
+	p, _ := inst.NewPlayer()
+	m, err := inst.OpenMediaFile("/a.ogg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, cancel, _ := p.Subscribe(vlcapi.MediaPlayerPlaying, vlcapi.MediaPlayerPaused,
+		vlcapi.MediaPlayerEndReached, vlcapi.MediaPlayerTimeChanged)
+	defer cancel()
+
+	if err = p.Play(); err == nil {
+		t.Fatal("Play without media succeeded")
+	}
+
+	p.SetMedia(m)
+	p.Play()
+	expect(t, events, vlcapi.MediaPlayerPlaying)
+
+	b.Advance(time.Second)
+	expect(t, events, vlcapi.MediaPlayerTimeChanged)
+
+	if v, _ := p.Position(); v != 0.5 {
+		t.Fatalf("position: have %v, want 0.5", v)
+	}
+
+	p.Pause()
+	b.Advance(time.Second)
+	expect(t, events, vlcapi.MediaPlayerPaused)
+
+	p.Play()
+	b.Advance(5 * time.Second)
+	expect(t, events, vlcapi.MediaPlayerPlaying, vlcapi.MediaPlayerTimeChanged, vlcapi.MediaPlayerEndReached)
+
+	if s := m.State(); s != vlcapi.MSEnded {
+		t.Fatalf("media state: have %v, want %v", s, vlcapi.MSEnded)
+	}
+}
+
+func TestListPlayer(t *testing.T) {
+	b := NewBackend()
+	b.Register("a", MediaInfo{Length: time.Second})
+	b.Register("b", MediaInfo{Length: time.Second})
+
+	l, _ := b.NewList()
+	for _, mrl := range []string{"a", "b"} {
+		m, _ := b.OpenMediaUri(mrl)
+		l.Add(m)
+	}
+
+	lp, _ := b.NewListPlayer()
+	lp.Set(l)
+
+	events, cancel, _ := lp.Subscribe(vlcapi.MediaListPlayerNextItemSet, vlcapi.MediaListPlayerPlayed)
+	defer cancel()
+
+	lp.Play()
+	b.Advance(time.Second)
+	b.Advance(time.Second)
+	expect(t, events, vlcapi.MediaListPlayerNextItemSet, vlcapi.MediaListPlayerNextItemSet,
+		vlcapi.MediaListPlayerPlayed)
+
+	if s, _ := lp.State(); s != vlcapi.MSEnded {
+		t.Fatalf("state: have %v, want %v", s, vlcapi.MSEnded)
+	}
+
+	lp.SetMode(vlcapi.PMLoop)
+	lp.PlayAt(1)
+	b.Advance(time.Second)
+	expect(t, events, vlcapi.MediaListPlayerNextItemSet, vlcapi.MediaListPlayerNextItemSet)
+
+	if m, _ := l.At(0); m.State() != vlcapi.MSPlaying {
+		t.Fatalf("first item is not playing")
+	}
+}
+
+func TestEventMedia(t *testing.T) {
+	b := NewBackend()
+	m, _ := b.OpenMediaUri("a")
+
+	l, _ := b.NewList()
+	events, cancel, _ := l.Subscribe(vlcapi.MediaListItemAdded, vlcapi.MediaListItemDeleted)
+	defer cancel()
+
+	l.Add(m)
+	l.Remove(0)
+
+	for _, want := range []vlcapi.EventType{vlcapi.MediaListItemAdded, vlcapi.MediaListItemDeleted} {
+		evt := <-events
+		p, ok := evt.Payload().(*vlcapi.ListItemEvent)
+		if evt.Type != want || !ok || p.Media != m || evt.Media() != m || p.Index != 0 {
+			t.Fatalf("event: have %v %+v, want %v for item 0", evt.Type, evt.Payload(), want)
+		}
+	}
+
+	p, _ := b.NewPlayer()
+	pevents, pcancel, _ := p.Subscribe(vlcapi.MediaPlayerMediaChanged)
+	defer pcancel()
+
+	p.SetMedia(m)
+	if c, ok := (<-pevents).Payload().(*vlcapi.MediaChangedEvent); !ok || c.Media != m {
+		t.Fatalf("media changed: have %+v, want %v", c, m)
+	}
+}
+
+func TestSeek(t *testing.T) {
+	b := NewBackend()
+	b.Register("a", MediaInfo{Length: time.Minute})
+	b.Register("live", MediaInfo{Unseekable: true})
+
+	p, _ := b.NewPlayer()
+	m, _ := b.OpenMediaUri("a")
+	p.SetMedia(m)
+	p.Play()
+
+	if err := p.Seek(20*time.Second, vlcapi.SeekPrecise); err != nil {
+		t.Fatal(err)
+	}
+
+	p.SeekBy(-30*time.Second, vlcapi.SeekPrecise)
+	if d, _ := p.Elapsed(); d != 0 {
+		t.Fatalf("time: have %v, want 0", d)
+	}
+
+	if err := p.SeekPosition(0.5, vlcapi.SeekPrecise); err != nil {
+		t.Fatal(err)
+	}
+
+	if d, _ := p.Elapsed(); d != 30*time.Second {
+		t.Fatalf("time: have %v, want 30s", d)
+	}
+
+	if err := p.Seek(time.Second, vlcapi.SeekFast); err == nil {
+		t.Fatal("fast Seek in media set up for precise seeking succeeded")
+	}
+
+	fast, _ := b.OpenMediaUri("a")
+	fast.AddOption(":input-fast-seek")
+	p.SetMedia(fast)
+	p.Play()
+
+	if err := p.Seek(time.Second, vlcapi.SeekFast); err != nil {
+		t.Fatal(err)
+	}
+
+	live, _ := b.OpenMediaUri("live")
+	p.SetMedia(live)
+	p.Play()
+
+	if err := p.Seek(time.Second, vlcapi.SeekPrecise); err == nil {
+		t.Fatal("Seek in unseekable media succeeded")
+	}
+}
+
+func TestWaitForState(t *testing.T) {
+	b := NewBackend()
+	b.Register("a", MediaInfo{Length: time.Second})
+	b.Register("broken", MediaInfo{Fail: true})
+
+	p, _ := b.NewPlayer()
+	m, _ := b.OpenMediaUri("a")
+	p.SetMedia(m)
+
+	ctx := context.Background()
+	if err := p.PlayAndWait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Playing again emits no events.
+	if err := p.PlayAndWait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan vlcapi.MediaState)
+	go func() {
+		s, _ := p.WaitForState(ctx, vlcapi.MSEnded)
+		done <- s
+	}()
+
+	b.Advance(time.Second)
+	if s := <-done; s != vlcapi.MSEnded {
+		t.Fatalf("state: have %v, want %v", s, vlcapi.MSEnded)
+	}
+
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.WaitForState(short, vlcapi.MSPaused); err != context.DeadlineExceeded {
+		t.Fatalf("WaitForState: have %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	broken, _ := b.OpenMediaUri("broken")
+	p.SetMedia(broken)
+
+	if err := p.PlayAndWait(ctx); err == nil {
+		t.Fatal("PlayAndWait on broken media succeeded")
+	}
+}
@@ -0,0 +1,430 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind of a VLM media.
+type VlmType int
+
+const (
+	VlmBroadcast VlmType = iota
+	VlmVOD
+)
+
+// Configuration and status of a VLM broadcast or VOD.
+type VlmMedia struct {
+	Name      string
+	Type      VlmType
+	Enabled   bool
+	Loop      bool   // Broadcasts only.
+	Mux       string // VODs only.
+	Inputs    []string
+	Output    string
+	Options   []string
+	Instances []*VlmInstance
+}
+
+// Status of a running instance of a VLM media.
+type VlmInstance struct {
+	Name          string
+	State         MediaState // One of MSPlaying, MSPaused or MSStopped.
+	Position      float32    // Between 0.0 and 1.0.
+	Time          time.Duration
+	Length        time.Duration
+	Rate          float32
+	Title         int
+	Chapter       int
+	Seekable      bool
+	PlaylistIndex int // Index of the input being played, starting at 1.
+}
+
+// VlmMedia returns the configuration and status of the named broadcast or
+// VOD.
+//
+// libvlc does not report media options. Options therefore only holds those
+// set through this Instance with Instance.VlmAddBroadcast(),
+// Instance.VlmAddVOD() or Instance.VlmChangeMedia().
+func (this *Instance) VlmMedia(name string) (*VlmMedia, error) {
+	if name == "" {
+		return nil, &VLCError{"VLM media name is empty"}
+	}
+
+	return vlmMedia(this.VlmMediaInfo, this.vlm, name)
+}
+
+// VlmList returns all broadcasts and VODs, including those configured by
+// other means, such as a .vlm file passed on the command line. See
+// Instance.VlmMedia() for which options are included.
+func (this *Instance) VlmList() ([]*VlmMedia, error) {
+	if this.ptr == nil {
+		return nil, &VLCError{"Instance is nil"}
+	}
+
+	return vlmList(this.VlmMediaInfo, this.vlm)
+}
+
+// vlmMedia queries the named media with info, which returns the output of
+// libvlc_vlm_show_media().
+func vlmMedia(info func(string) (string, error), st *vlmState, name string) (*VlmMedia, error) {
+	s, err := info(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if s == "" {
+		return nil, &VLCError{"Unknown VLM media: " + name}
+	}
+
+	v, err := parseVlm(s)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := v.(vlmObject)
+	if !ok {
+		return nil, &VLCError{"Unexpected VLM media info for " + name}
+	}
+
+	m := newVlmMedia(name, obj)
+	m.Options = st.options(name)
+	return m, nil
+}
+
+// vlmList queries all media with info. The summary only holds the name,
+// type and state of each media, so they are queried one by one.
+func vlmList(info func(string) (string, error), st *vlmState) ([]*VlmMedia, error) {
+	s, err := info("")
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := vlmNames(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*VlmMedia
+
+	for _, name := range names {
+		m, err := vlmMedia(info, st, name)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, m)
+	}
+
+	return list, nil
+}
+
+// vlmNames returns the media names listed in the output of "show".
+// Without media, the list is replaced by a count.
+func vlmNames(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := parseVlm(s)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := v.(vlmObject)
+	if !ok {
+		return nil, &VLCError{"Unexpected VLM media list"}
+	}
+
+	var names []string
+
+	for _, e := range obj.list("media") {
+		if o, ok := e.(vlmObject); ok && o.str("name") != "" {
+			names = append(names, o.str("name"))
+		}
+	}
+
+	return names, nil
+}
+
+// newVlmMedia decodes the output of "show <name>". It includes the media
+// name, but name, as passed to libvlc, is used instead.
+func newVlmMedia(name string, obj vlmObject) *VlmMedia {
+	m := &VlmMedia{
+		Name:    name,
+		Enabled: obj.str("enabled") == "yes",
+		Loop:    obj.str("loop") == "yes",
+		Mux:     obj.str("mux"),
+		Output:  obj.str("output"),
+	}
+
+	if obj.str("type") == "vod" {
+		m.Type = VlmVOD
+	}
+
+	for _, v := range obj.list("inputs") {
+		if s, ok := v.(string); ok {
+			m.Inputs = append(m.Inputs, s)
+		}
+	}
+
+	// Each instance is stored under the same key.
+	if instances, ok := obj.get("instances").(vlmObject); ok {
+		for _, p := range instances {
+			if o, ok := p.value.(vlmObject); ok && p.key == "instance" {
+				m.Instances = append(m.Instances, newVlmInstance(o))
+			}
+		}
+	}
+
+	return m
+}
+
+func newVlmInstance(obj vlmObject) *VlmInstance {
+	i := &VlmInstance{
+		Name:          obj.str("name"),
+		State:         MSStopped,
+		Position:      float32(obj.float("position")),
+		Time:          time.Duration(obj.int("time")) * time.Microsecond,
+		Length:        time.Duration(obj.int("length")) * time.Microsecond,
+		Rate:          float32(obj.float("rate")),
+		Title:         int(obj.int("title")),
+		Chapter:       int(obj.int("chapter")),
+		Seekable:      obj.int("can-seek") != 0,
+		PlaylistIndex: int(obj.int("playlistindex")),
+	}
+
+	switch obj.str("state") {
+	case "playing":
+		i.State = MSPlaying
+	case "paused":
+		i.State = MSPaused
+	}
+
+	return i
+}
+
+// Options of media created through an Instance, which libvlc does not
+// report.
+type vlmState struct {
+	m    sync.Mutex
+	opts map[string][]string
+}
+
+func newVlmState() *vlmState {
+	return &vlmState{opts: make(map[string][]string)}
+}
+
+func (this *vlmState) options(name string) []string {
+	this.m.Lock()
+	defer this.m.Unlock()
+	return append([]string(nil), this.opts[name]...)
+}
+
+// set records the options of the named media.
+func (this *vlmState) set(name string, options []string) {
+	this.m.Lock()
+	this.opts[name] = append([]string{}, options...)
+	this.m.Unlock()
+}
+
+// remove forgets the named media.
+func (this *vlmState) remove(name string) {
+	this.m.Lock()
+	delete(this.opts, name)
+	this.m.Unlock()
+}
+
+func (this *vlmState) clear() {
+	this.m.Lock()
+	this.opts = make(map[string][]string)
+	this.m.Unlock()
+}
+
+// libvlc formats VLM information as JSON-like text without escaping
+// values, so it can not be decoded by encoding/json. The parser below
+// relies on the layout libvlc produces instead: every value is followed by
+// a comma or space and a newline.
+//
+// Objects are kept as ordered key/value pairs, as keys may repeat. Lists are
+// []interface{}, values are strings, and null is nil.
+
+type vlmPair struct {
+	key   string
+	value interface{}
+}
+
+type vlmObject []vlmPair
+
+func (this vlmObject) get(key string) interface{} {
+	for _, p := range this {
+		if p.key == key {
+			return p.value
+		}
+	}
+	return nil
+}
+
+func (this vlmObject) str(key string) string {
+	s, _ := this.get(key).(string)
+	return s
+}
+
+func (this vlmObject) list(key string) []interface{} {
+	l, _ := this.get(key).([]interface{})
+	return l
+}
+
+func (this vlmObject) int(key string) int64 {
+	v, _ := strconv.ParseInt(this.str(key), 10, 64)
+	return v
+}
+
+func (this vlmObject) float(key string) float64 {
+	// The value is formatted with the C locale of the libvlc process.
+	v, _ := strconv.ParseFloat(strings.Replace(this.str(key), ",", ".", 1), 64)
+	return v
+}
+
+func parseVlm(s string) (interface{}, error) {
+	p := &vlmParser{s: s}
+
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+type vlmParser struct {
+	s   string
+	pos int
+}
+
+func (p *vlmParser) errorf(format string, args ...interface{}) error {
+	return &VLCError{fmt.Sprintf("Invalid VLM info at offset %d: ", p.pos) + fmt.Sprintf(format, args...)}
+}
+
+func (p *vlmParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n,", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *vlmParser) value() (interface{}, error) {
+	p.skipSpace()
+
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("unexpected end")
+	}
+
+	switch p.s[p.pos] {
+	case '{':
+		p.pos++
+		return p.object()
+
+	case '[':
+		p.pos++
+		return p.list()
+
+	case '"':
+		p.pos++
+		return p.str(), nil
+	}
+
+	if strings.HasPrefix(p.s[p.pos:], "null") {
+		p.pos += 4
+		return nil, nil
+	}
+
+	return nil, p.errorf("unexpected %q", p.s[p.pos])
+}
+
+func (p *vlmParser) object() (vlmObject, error) {
+	var obj vlmObject
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("missing '}'")
+		}
+
+		if p.s[p.pos] == '}' {
+			p.pos++
+			return obj, nil
+		}
+
+		if p.s[p.pos] != '"' {
+			return nil, p.errorf("expected key")
+		}
+
+		// Keys are followed by a colon and a space.
+		end := strings.Index(p.s[p.pos+1:], "\": ")
+		if end < 0 {
+			return nil, p.errorf("unterminated key")
+		}
+
+		key := p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 4
+
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		obj = append(obj, vlmPair{key, v})
+	}
+}
+
+func (p *vlmParser) list() ([]interface{}, error) {
+	var list []interface{}
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("missing ']'")
+		}
+
+		if p.s[p.pos] == ']' {
+			p.pos++
+			return list, nil
+		}
+
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, v)
+	}
+}
+
+// str reads a string value up to the closing quote which is followed by a
+// separator and a newline, or the end of input.
+func (p *vlmParser) str() string {
+	start := p.pos
+
+	for i := start; i < len(p.s); i++ {
+		if p.s[i] != '"' {
+			continue
+		}
+
+		rest := p.s[i+1:]
+		if rest == "" || strings.HasPrefix(rest, "\n") ||
+			strings.HasPrefix(rest, ",\n") || strings.HasPrefix(rest, " \n") {
+			p.pos = i + 1
+			return p.s[start:i]
+		}
+	}
+
+	p.pos = len(p.s)
+	return p.s[start:]
+}
@@ -0,0 +1,172 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Output of libvlc_vlm_show_media() for a playing broadcast and an idle VOD,
+// as formatted by libvlc 3. Options are listed by name only, so they come
+// out as null.
+const (
+	vlmShowBroadcast = "{\n\t\"name\": \"lobby\",\n\t\"type\": \"broadcast\",\n\t\"enabled\": \"yes\",\n\t" +
+		"\"loop\": \"no\",\n\t\"inputs\": [\n\t\t\"file:///srv/a b.ogg\",\n\t\t\"dvd://\" \n\t\t\n\t],\n\t" +
+		"\"output\": \"#std{access=udp,mux=ts,dst=239.0.0.1:1234}\",\n\t" +
+		"\"options\": [\n\t\tnull \n\t\t\n\t],\n\t" +
+		"\"instances\": {\n\t\t\"instance\": {\n\t\t\t\"name\": \"default\",\n\t\t\t\"state\": \"playing\",\n\t\t\t" +
+		"\"position\": \"0.250000\",\n\t\t\t\"time\": \"15000000\",\n\t\t\t\"length\": \"60000000\",\n\t\t\t" +
+		"\"rate\": \"1.000000\",\n\t\t\t\"title\": \"0\",\n\t\t\t\"chapter\": \"0\",\n\t\t\t" +
+		"\"can-seek\": \"1\",\n\t\t\t\"playlistindex\": \"2\" \n\t\t\t\n\t\t} \n\t\t\n\t} \n\t\n} \n\n"
+
+	vlmShowVOD = "{\n\t\"name\": \"movie\",\n\t\"type\": \"vod\",\n\t\"enabled\": \"no\",\n\t\"mux\": \"mp2t\",\n\t" +
+		"\"inputs\": null,\n\t\"output\": \"\",\n\t\"options\": null,\n\t\"instances\": null \n\t\n} \n\n"
+)
+
+// Output of libvlc_vlm_show_media() with an empty name, which summarizes all
+// media, with the media above and without any.
+const (
+	vlmShowAll = "{\n\t\"media\": [\n\t\t{\n\t\t\t\"name\": \"lobby\",\n\t\t\t\"type\": \"broadcast\",\n\t\t\t" +
+		"\"enabled\": \"yes\",\n\t\t\t\"loop\": \"no\" \n\t\t\t\n\t\t},\n\t\t{\n\t\t\t\"name\": \"movie\",\n\t\t\t" +
+		"\"type\": \"vod\",\n\t\t\t\"enabled\": \"no\",\n\t\t\t\"mux\": \"mp2t\" \n\t\t\t\n\t\t} \n\t\t\n\t],\n\t" +
+		"\"schedule\": null \n\t\n}\n"
+
+	vlmShowNone = "{\n\t\"media\": \"( 0 broadcast - 0 vod )\",\n\t\"schedule\": null \n\t\n}\n"
+)
+
+// vlmInfo returns the fixtures above as VlmMediaInfo() would.
+func vlmInfo(name string) (string, error) {
+	switch name {
+	case "":
+		return vlmShowAll, nil
+	case "lobby":
+		return vlmShowBroadcast, nil
+	case "movie":
+		return vlmShowVOD, nil
+	}
+	return "", nil
+}
+
+func TestParseVlm(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		info string
+		want *VlmMedia
+	}{
+		{"lobby", vlmShowBroadcast, &VlmMedia{
+			Name:    "lobby",
+			Type:    VlmBroadcast,
+			Enabled: true,
+			Inputs:  []string{"file:///srv/a b.ogg", "dvd://"},
+			Output:  "#std{access=udp,mux=ts,dst=239.0.0.1:1234}",
+			Instances: []*VlmInstance{{
+				Name:          "default",
+				State:         MSPlaying,
+				Position:      0.25,
+				Time:          15 * time.Second,
+				Length:        time.Minute,
+				Rate:          1,
+				Seekable:      true,
+				PlaylistIndex: 2,
+			}},
+		}},
+		{"movie", vlmShowVOD, &VlmMedia{
+			Name: "movie",
+			Type: VlmVOD,
+			Mux:  "mp2t",
+		}},
+	} {
+		v, err := parseVlm(tt.info)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		obj, ok := v.(vlmObject)
+		if !ok {
+			t.Fatalf("%s: have %T, want an object", tt.name, v)
+		}
+
+		if have := newVlmMedia(tt.name, obj); !reflect.DeepEqual(have, tt.want) {
+			t.Fatalf("%s:\nhave %+v\nwant %+v", tt.name, have, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "{\n\t\"type\": \"vod\",\n", "{\n\t\"inputs\": [\n\t\t\"a\",\n", "{\n\ttype\n}"} {
+		if _, err := parseVlm(s); err == nil {
+			t.Fatalf("parseVlm(%q): expected error", s)
+		}
+	}
+}
+
+func TestVlmNames(t *testing.T) {
+	for _, tt := range []struct {
+		info string
+		want []string
+	}{
+		{vlmShowAll, []string{"lobby", "movie"}},
+		{vlmShowNone, nil},
+		{"", nil},
+	} {
+		have, err := vlmNames(tt.info)
+		if err != nil {
+			t.Fatalf("vlmNames(%q): %v", tt.info, err)
+		}
+
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Fatalf("vlmNames(%q): have %q, want %q", tt.info, have, tt.want)
+		}
+	}
+}
+
+func TestVlmList(t *testing.T) {
+	// Only lobby was created through the Instance.
+	st := newVlmState()
+	st.set("lobby", []string{"sout-keep"})
+
+	list, err := vlmList(vlmInfo, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 2 {
+		t.Fatalf("media count: have %d, want 2", len(list))
+	}
+
+	if m := list[0]; m.Name != "lobby" || !reflect.DeepEqual(m.Options, []string{"sout-keep"}) {
+		t.Fatalf("lobby: have %q with options %q", m.Name, m.Options)
+	}
+
+	if m := list[1]; m.Name != "movie" || m.Type != VlmVOD || m.Options != nil {
+		t.Fatalf("movie: have %q of type %d with options %q", m.Name, m.Type, m.Options)
+	}
+
+	if _, err = vlmMedia(vlmInfo, st, "unknown"); err == nil {
+		t.Fatalf("vlmMedia(unknown): expected error")
+	}
+}
+
+func TestVlmState(t *testing.T) {
+	s := newVlmState()
+	s.set("b", []string{"sout-keep"})
+	s.set("a", nil)
+	s.set("b", []string{"no-audio"})
+	s.remove("a")
+	s.remove("unknown")
+
+	if have, want := s.options("b"), []string{"no-audio"}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("options: have %v, want %v", have, want)
+	}
+
+	if have := s.options("a"); len(have) != 0 {
+		t.Fatalf("options after remove: %v", have)
+	}
+
+	s.clear()
+	if have := s.options("b"); len(have) != 0 {
+		t.Fatalf("options after clear: %v", have)
+	}
+}
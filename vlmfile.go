@@ -0,0 +1,440 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const vlmHeader = "\n# VLC media player VLM command batch\n# http://www.videolan.org/vlc/\n\n"
+
+// VlmExport writes all broadcasts and VODs to w as a VLM command batch, the
+// format of VLC's .vlm files. Broadcasts which are playing get a control
+// command to start them again when the file is loaded.
+//
+// Media configured by other means than this Instance are exported without
+// options. See Instance.VlmMedia().
+func (this *Instance) VlmExport(w io.Writer) error {
+	list, err := this.VlmList()
+	if err != nil {
+		return err
+	}
+
+	return vlmExport(w, list)
+}
+
+// vlmExport writes list to w as a VLM command batch.
+func vlmExport(w io.Writer, list []*VlmMedia) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(vlmHeader)
+
+	var playing []string
+
+	for _, m := range list {
+		name := vlmQuote(m.Name)
+
+		kind := "broadcast"
+		if m.Type == VlmVOD {
+			kind = "vod"
+		}
+
+		state := "disabled"
+		if m.Enabled {
+			state = "enabled"
+		}
+
+		if m.Type == VlmBroadcast && m.Loop {
+			state += " loop"
+		}
+
+		fmt.Fprintf(bw, "new %s %s %s\n", name, kind, state)
+
+		for _, in := range m.Inputs {
+			fmt.Fprintf(bw, "setup %s input %s\n", name, vlmQuote(in))
+		}
+
+		if m.Output != "" {
+			fmt.Fprintf(bw, "setup %s output %s\n", name, vlmQuote(m.Output))
+		}
+
+		for _, o := range m.Options {
+			fmt.Fprintf(bw, "setup %s option %s\n", name, vlmQuote(o))
+		}
+
+		if m.Type == VlmVOD && m.Mux != "" {
+			fmt.Fprintf(bw, "setup %s mux %s\n", name, vlmQuote(m.Mux))
+		}
+
+		for _, i := range m.Instances {
+			if i.State == MSPlaying && m.Type == VlmBroadcast {
+				playing = append(playing, name)
+				break
+			}
+		}
+	}
+
+	if len(playing) > 0 {
+		bw.WriteString("\n")
+	}
+
+	for _, name := range playing {
+		fmt.Fprintf(bw, "control %s play\n", name)
+	}
+
+	return bw.Flush()
+}
+
+// VlmImport reads a VLM command batch, such as a .vlm file written by VLC or
+// Instance.VlmExport(), and executes its new, setup, control and del
+// commands. Empty lines and lines starting with '#' are ignored.
+//
+// Import stops at the first command which fails or is not supported, such as
+// schedule commands. Commands before it remain in effect.
+func (this *Instance) VlmImport(r io.Reader) error {
+	if this.ptr == nil {
+		return &VLCError{"Instance is nil"}
+	}
+
+	im := &vlmImporter{inst: this, media: make(map[string]*vlmPending)}
+	sc := bufio.NewScanner(r)
+
+	for line := 1; sc.Scan(); line++ {
+		args, err := vlmSplit(sc.Text())
+		if err != nil {
+			return &VLCError{fmt.Sprintf("VLM line %d: %v", line, err)}
+		}
+
+		if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+			continue
+		}
+
+		if err = im.exec(args); err != nil {
+			return &VLCError{fmt.Sprintf("VLM line %d: %v", line, err)}
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	return im.flush()
+}
+
+// A media being imported. Its configuration is collected from new and setup
+// commands, and applied when it is first needed.
+type vlmPending struct {
+	VlmMedia
+	created bool // The media exists in libvlc.
+	dirty   bool // The configuration has changed since it was applied.
+}
+
+type vlmImporter struct {
+	inst  *Instance
+	media map[string]*vlmPending
+	order []string
+}
+
+func (this *vlmImporter) exec(args []string) error {
+	switch args[0] {
+	case "new":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: new <name> broadcast|vod [properties]")
+		}
+
+		if _, ok := this.media[args[1]]; ok {
+			return fmt.Errorf("media %q already exists", args[1])
+		}
+
+		m := &vlmPending{VlmMedia: VlmMedia{Name: args[1]}, dirty: true}
+
+		switch args[2] {
+		case "broadcast":
+		case "vod":
+			m.Type = VlmVOD
+		default:
+			return fmt.Errorf("unsupported media type %q", args[2])
+		}
+
+		this.media[m.Name] = m
+		this.order = append(this.order, m.Name)
+		return m.setup(args[3:])
+
+	case "setup":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: setup <name> <properties>")
+		}
+
+		m, ok := this.media[args[1]]
+		if !ok {
+			return fmt.Errorf("unknown media %q", args[1])
+		}
+
+		return m.setup(args[2:])
+
+	case "control":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: control <name> [instance] play|pause|stop|seek")
+		}
+
+		m, ok := this.media[args[1]]
+		if !ok {
+			return fmt.Errorf("unknown media %q", args[1])
+		}
+
+		if err := this.apply(m); err != nil {
+			return err
+		}
+
+		cmd := args[2:]
+		if !vlmIsControl(cmd[0]) && len(cmd) > 1 {
+			cmd = cmd[1:] // Skip the instance name.
+		}
+
+		switch cmd[0] {
+		case "play":
+			return this.inst.VlmPlay(m.Name)
+		case "pause":
+			return this.inst.VlmPause(m.Name)
+		case "stop":
+			return this.inst.VlmStop(m.Name)
+		case "seek":
+			if len(cmd) < 2 {
+				return fmt.Errorf("usage: control <name> seek <percentage>")
+			}
+
+			v, err := strconv.ParseFloat(strings.TrimSuffix(cmd[1], "%"), 32)
+			if err != nil {
+				return fmt.Errorf("invalid seek position %q", cmd[1])
+			}
+
+			return this.inst.VlmSeek(m.Name, float32(v))
+		}
+
+		return fmt.Errorf("unsupported control command %q", cmd[0])
+
+	case "del":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: del <name>")
+		}
+
+		m, ok := this.media[args[1]]
+		if !ok {
+			return fmt.Errorf("unknown media %q", args[1])
+		}
+
+		delete(this.media, m.Name)
+		for i, name := range this.order {
+			if name == m.Name {
+				this.order = append(this.order[:i], this.order[i+1:]...)
+				break
+			}
+		}
+
+		if m.created {
+			return this.inst.VlmDelete(m.Name)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unsupported command %q", args[0])
+}
+
+// setup applies media properties from a new or setup command.
+func (this *vlmPending) setup(args []string) error {
+	for i := 0; i < len(args); i++ {
+		switch p := args[i]; p {
+		case "enabled", "disabled":
+			this.Enabled = p == "enabled"
+		case "loop", "unloop":
+			this.Loop = p == "loop"
+
+		case "input", "output", "option", "mux", "inputdel":
+			if i+1 >= len(args) {
+				return fmt.Errorf("missing value for %s", p)
+			}
+
+			i++
+			v := args[i]
+
+			switch p {
+			case "input":
+				this.Inputs = append(this.Inputs, v)
+			case "output":
+				this.Output = v
+			case "option":
+				this.Options = append(this.Options, v)
+			case "mux":
+				this.Mux = v
+			case "inputdel":
+				if v == "all" {
+					this.Inputs = nil
+					break
+				}
+
+				for j, in := range this.Inputs {
+					if in == v {
+						this.Inputs = append(this.Inputs[:j], this.Inputs[j+1:]...)
+						break
+					}
+				}
+			}
+
+		default:
+			return fmt.Errorf("unsupported property %q", p)
+		}
+	}
+
+	this.dirty = true
+	return nil
+}
+
+// apply creates or updates the media in libvlc if its configuration changed.
+func (this *vlmImporter) apply(m *vlmPending) error {
+	if !m.dirty {
+		return nil
+	}
+
+	var input string
+	if len(m.Inputs) > 0 {
+		input = m.Inputs[0]
+	}
+
+	var err error
+
+	switch {
+	case m.created:
+		err = this.inst.VlmChangeMedia(m.Name, input, m.Output, m.Options, m.Enabled, m.Loop)
+	case m.Type == VlmVOD:
+		err = this.inst.VlmAddVOD(m.Name, input, m.Output, m.Mux, m.Options, m.Enabled)
+	default:
+		err = this.inst.VlmAddBroadcast(m.Name, input, m.Output, m.Options, m.Enabled, m.Loop)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i < len(m.Inputs) && err == nil; i++ {
+		err = this.inst.VlmAddInput(m.Name, m.Inputs[i])
+	}
+
+	if err == nil && m.created && m.Type == VlmVOD && m.Mux != "" {
+		err = this.inst.VlmSetMux(m.Name, m.Mux)
+	}
+
+	m.created = true
+	m.dirty = false
+	return err
+}
+
+// flush applies all outstanding configuration, in file order.
+func (this *vlmImporter) flush() error {
+	for _, name := range this.order {
+		if err := this.apply(this.media[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func vlmIsControl(s string) bool {
+	return s == "play" || s == "pause" || s == "stop" || s == "seek"
+}
+
+// vlmQuote quotes s as a single VLM command argument, if needed. Stream
+// output chains are written as-is, as VLC does not unescape arguments which
+// start with '#'.
+func vlmQuote(s string) string {
+	if s == "" {
+		return `""`
+	}
+
+	if s[0] == '#' && !strings.ContainsAny(s, " \t") {
+		return s
+	}
+
+	if !strings.ContainsAny(s, " \t\"'\\#") {
+		return s
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// vlmSplit splits a VLM command line into arguments, following VLC's rules:
+// arguments are separated by spaces, may be quoted with single or double
+// quotes, and a backslash escapes the next character outside of single
+// quotes. Arguments starting with '#' are taken literally.
+func vlmSplit(line string) ([]string, error) {
+	var args []string
+
+	for i := 0; i < len(line); {
+		if line[i] == ' ' || line[i] == '\t' || line[i] == '\r' {
+			i++
+			continue
+		}
+
+		var sb strings.Builder
+		var quote byte
+		literal := line[i] == '#'
+
+		for ; i < len(line); i++ {
+			c := line[i]
+
+			if quote == 0 {
+				if c == ' ' || c == '\t' || c == '\r' {
+					break
+				}
+
+				if c == '"' || c == '\'' {
+					quote = c
+					if literal {
+						sb.WriteByte(c)
+					}
+					continue
+				}
+
+				if c == '\\' && !literal && i+1 < len(line) {
+					i++
+					c = line[i]
+				}
+
+				sb.WriteByte(c)
+				continue
+			}
+
+			if c == quote {
+				quote = 0
+				if literal {
+					sb.WriteByte(c)
+				}
+				continue
+			}
+
+			if c == '\\' && quote == '"' && i+1 < len(line) {
+				if literal {
+					sb.WriteByte(c)
+				}
+				i++
+				c = line[i]
+			}
+
+			sb.WriteByte(c)
+		}
+
+		if quote != 0 {
+			return nil, fmt.Errorf("missing closing %c", quote)
+		}
+
+		args = append(args, sb.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,100 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestVlmExport(t *testing.T) {
+	// Neither media was created through the Instance.
+	list, err := vlmList(vlmInfo, newVlmState())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = vlmExport(&buf, list); err != nil {
+		t.Fatal(err)
+	}
+
+	want := vlmHeader +
+		"new lobby broadcast enabled\n" +
+		"setup lobby input \"file:///srv/a b.ogg\"\n" +
+		"setup lobby input dvd://\n" +
+		"setup lobby output #std{access=udp,mux=ts,dst=239.0.0.1:1234}\n" +
+		"new movie vod disabled\n" +
+		"setup movie mux mp2t\n" +
+		"\n" +
+		"control lobby play\n"
+
+	if have := buf.String(); have != want {
+		t.Fatalf("have:\n%s\nwant:\n%s", have, want)
+	}
+}
+
+func TestVlmQuote(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"", `""`},
+		{"lobby", "lobby"},
+		{"file:///srv/a b.ogg", `"file:///srv/a b.ogg"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\media\a.ogg`, `"C:\\media\\a.ogg"`},
+		{"it's", `"it's"`},
+		{"x#y", `"x#y"`},
+		{"#std{access=file,dst=out.ts}", "#std{access=file,dst=out.ts}"},
+		{`#std{dst="a b.ts"}`, `"#std{dst=\"a b.ts\"}"`},
+	} {
+		have := vlmQuote(tt.in)
+		if have != tt.want {
+			t.Fatalf("vlmQuote(%q): have %s, want %s", tt.in, have, tt.want)
+		}
+
+		args, err := vlmSplit(have)
+		if err != nil {
+			t.Fatalf("vlmSplit(%s): %v", have, err)
+		}
+
+		if len(args) != 1 || args[0] != tt.in {
+			t.Fatalf("vlmSplit(%s): have %q, want %q", have, args, tt.in)
+		}
+	}
+}
+
+func TestVlmSplit(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"  \t\r", nil},
+		{"new lobby broadcast enabled", []string{"new", "lobby", "broadcast", "enabled"}},
+		{`setup lobby input "file:///a b.ogg"`, []string{"setup", "lobby", "input", "file:///a b.ogg"}},
+		{`setup lobby option 'a "b" \c'`, []string{"setup", "lobby", "option", `a "b" \c`}},
+		{`setup lobby input a\ b.ogg`, []string{"setup", "lobby", "input", "a b.ogg"}},
+		{`setup lobby output #std{dst="a b.ts",mux=ts}`, []string{"setup", "lobby", "output", `#std{dst="a b.ts",mux=ts}`}},
+		{`setup lobby output #std{dst=a\b}`, []string{"setup", "lobby", "output", `#std{dst=a\b}`}},
+		{"# comment line", []string{"#", "comment", "line"}},
+	} {
+		have, err := vlmSplit(tt.line)
+		if err != nil {
+			t.Fatalf("vlmSplit(%s): %v", tt.line, err)
+		}
+
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Fatalf("vlmSplit(%s): have %q, want %q", tt.line, have, tt.want)
+		}
+	}
+
+	for _, line := range []string{`new "lobby`, `setup lobby input 'a`} {
+		if _, err := vlmSplit(line); err == nil {
+			t.Fatalf("vlmSplit(%s): expected error", line)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// This work is subject to the CC0 1.0 Universal (CC0 1.0) Public Domain Dedication
+// license. Its contents can be found at:
+// http://creativecommons.org/publicdomain/zero/1.0
+
+package vlc
+
+import (
+	"context"
+	"fmt"
+)
+
+// WaitForState blocks until the player reaches one of the given states, and
+// returns it. If the player is already in one of them, it returns at once.
+//
+// If the player fails, ends or is stopped while waiting, and that state was
+// not asked for, the state is returned with an error. If ctx is done first,
+// the current state is returned with ctx.Err().
+//
+// The player's state is only checked once before waiting; after that, only
+// state changes are considered. So a player which is still stopped after
+// Play() is waited for, rather than reported as stopped.
+func (this *Player) WaitForState(ctx context.Context, states ...MediaState) (MediaState, error) {
+	if len(states) == 0 {
+		return 0, &VLCError{"No states to wait for"}
+	}
+
+	events, cancel, err := this.subscribeStates()
+	if err != nil {
+		return 0, err
+	}
+
+	defer cancel()
+
+	s, err := this.State()
+	if err != nil {
+		return 0, err
+	}
+
+	if hasState(states, s) {
+		return s, nil
+	}
+
+	return this.waitForState(ctx, events, states)
+}
+
+// PlayAndWait starts playback and blocks until it has actually started. It
+// returns nil once the player is playing, or has already reached the end of
+// a short media. If playback fails, or the player is stopped first, an error
+// is returned. A player which has ended is stopped and played again.
+//
+// If ctx is done first, ctx.Err() is returned and the player is left as it
+// is; call Stop() to abort playback.
+func (this *Player) PlayAndWait(ctx context.Context) error {
+	s, err := this.State()
+	if err != nil {
+		return err
+	}
+
+	// libvlc keeps the ended input around, and Play() would only resume it.
+	if s == MSEnded {
+		if err = this.Stop(); err != nil {
+			return err
+		}
+	}
+
+	// Subscribe first, so the transitions caused by Play() are not missed.
+	events, cancel, err := this.subscribeStates()
+	if err != nil {
+		return err
+	}
+
+	defer cancel()
+
+	if err = this.Play(); err != nil {
+		return err
+	}
+
+	// Play() does not change the state of a player which is already playing,
+	// and may have run into the end or an error by now.
+	if s, err = this.State(); err != nil {
+		return err
+	}
+
+	switch s {
+	case MSPlaying, MSEnded:
+		return nil
+	case MSError:
+		return this.waitError("Playback failed")
+	}
+
+	_, err = this.waitForState(ctx, events, []MediaState{MSPlaying, MSEnded})
+	return err
+}
+
+// subscribeStates subscribes to all events which report a player state
+// change.
+func (this *Player) subscribeStates() (<-chan *Event, func(), error) {
+	types := make([]EventType, 0, len(playerStates))
+	for t := range playerStates {
+		types = append(types, t)
+	}
+
+	return this.Subscribe(types...)
+}
+
+func (this *Player) waitForState(ctx context.Context, events <-chan *Event, states []MediaState) (MediaState, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			s, _ := this.State()
+			return s, ctx.Err()
+
+		case evt := <-events:
+			s := evt.Payload().(*StateChangedEvent).State
+
+			switch {
+			case hasState(states, s):
+				return s, nil
+			case s == MSError:
+				return s, this.waitError("Playback failed")
+			case s == MSEnded, s == MSStopped:
+				return s, this.waitError(fmt.Sprintf("Playback %s while waiting for %v", s, states))
+			}
+		}
+	}
+}
+
+// waitError returns an error with the given message and the MRL of the
+// player's media.
+func (this *Player) waitError(msg string) error {
+	if m, err := this.Media(); err == nil && m != nil {
+		msg += ": " + m.Mrl()
+		m.Release()
+	}
+
+	return &VLCError{msg}
+}
+
+func hasState(states []MediaState, s MediaState) bool {
+	for _, v := range states {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}